@@ -0,0 +1,431 @@
+// Package announcements provides handlers for broadcasting announcements to group members.
+package announcements
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+	"github.com/ship-labs/meet-loop-api/notifications"
+	"github.com/ship-labs/meet-loop-api/preferences"
+)
+
+const (
+	defaultLimit    = 20
+	maxLimit        = 100
+	deliveryTimeout = 5 * time.Minute
+	dueBatchSize    = 500
+)
+
+// CreateAnnouncement stores an announcement on a group and fans it out to every member, or only to the
+// members who RSVP'd to event_id when it is set. In-app delivery is always included so the announcement
+// shows up in the members' feed. Each member only receives it on the channels their notification
+// preferences allow, and deliveries falling within their quiet hours are held back until those end. The
+// deliveries are sent by DeliverScheduled, which is woken up once they are committed.
+func CreateAnnouncement(store *sqlc.Store, dispatcher *notifications.Dispatcher) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Title    string   `json:"title" zog:"title"`
+			Body     string   `json:"body" zog:"body"`
+			EventID  *int64   `json:"event_id" zog:"event_id"`
+			Channels []string `json:"channels" zog:"channels"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"Title":    zog.String().Trim().Required(zog.Message("Title is required")).Max(200),
+			"Body":     zog.String().Trim().Required(zog.Message("Body is required")),
+			"EventID":  zog.Ptr(zog.Int64().GT(0)),
+			"Channels": zog.Slice(zog.String()).Optional(),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating announcement: %w", err))
+		}
+
+		channels := []notifications.Channel{notifications.InApp}
+		for _, c := range body.Channels {
+			channel := notifications.Channel(c)
+			if !dispatcher.Supports(channel) {
+				return middleware.Error(fmt.Errorf("%w: %s", internal.ErrInvalidRequest, notifications.ErrUnsupportedChannel))
+			}
+			if channel != notifications.InApp {
+				channels = append(channels, channel)
+			}
+		}
+
+		if _, err := groups.FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		var eventID pgtype.Int8
+		if body.EventID != nil {
+			event, err := store.GetEvent(r.Context(), *body.EventID)
+			if errors.Is(err, pgx.ErrNoRows) || (err == nil && event.GroupID != groupID) {
+				return middleware.Error(fmt.Errorf("event %w", internal.ErrNotExist))
+			}
+			if err != nil {
+				return middleware.Error(fmt.Errorf("getting event: %w", err))
+			}
+			eventID = pgtype.Int8{Int64: event.ID, Valid: true}
+		}
+
+		// Admins of a parent group are not necessarily members of the chapter they announce to.
+		var authorID pgtype.Int8
+		if author, err := groups.FindMember(r.Context(), store, groupID); err == nil {
+			authorID = pgtype.Int8{Int64: author.ID, Valid: true}
+		}
+
+		var announcement sqlc.Announcement
+		var recipients []sqlc.Member
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			announcement, err = q.CreateAnnouncement(r.Context(), sqlc.CreateAnnouncementParams{
+				GroupID:        groupID,
+				EventID:        eventID,
+				AuthorMemberID: authorID,
+				Title:          body.Title,
+				Body:           body.Body,
+			})
+			if err != nil {
+				return fmt.Errorf("creating announcement: %w", err)
+			}
+
+			recipients, err = q.ListAnnouncementRecipients(r.Context(), sqlc.ListAnnouncementRecipientsParams{
+				GroupID: groupID,
+				EventID: eventID,
+			})
+			if err != nil {
+				return fmt.Errorf("listing recipients: %w", err)
+			}
+
+			userIDs := make([]pgtype.UUID, 0, len(recipients))
+			for _, recipient := range recipients {
+				userIDs = append(userIDs, recipient.UserID)
+			}
+
+			userPreferences, err := preferences.Load(r.Context(), q, pgtype.Int8{Int64: groupID, Valid: true}, userIDs)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			memberIDs := make([]int64, 0, len(recipients)*len(channels))
+			deliveryChannels := make([]string, 0, len(recipients)*len(channels))
+			deliverAfters := make([]pgtype.Timestamp, 0, len(recipients)*len(channels))
+			for _, recipient := range recipients {
+				p := userPreferences[recipient.UserID]
+				for _, channel := range channels {
+					if !p.Allows(notifications.Announcements, channel) {
+						continue
+					}
+					deliverAfter := cmp.Or(p.DeliverAfter(channel, now), now)
+					memberIDs = append(memberIDs, recipient.ID)
+					deliveryChannels = append(deliveryChannels, string(channel))
+					deliverAfters = append(deliverAfters, pgtype.Timestamp{Time: deliverAfter.UTC(), Valid: true})
+				}
+			}
+
+			_, err = q.CreateAnnouncementDeliveries(r.Context(), sqlc.CreateAnnouncementDeliveriesParams{
+				AnnouncementID: announcement.ID,
+				MemberIds:      memberIDs,
+				Channels:       deliveryChannels,
+				DeliverAfters:  deliverAfters,
+			})
+			if err != nil {
+				return fmt.Errorf("creating deliveries: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("creating announcement: %w", err))
+		}
+
+		// The deliveries are claimed like any other due ones, so they are still sent when this instance stops
+		// before sending them.
+		sqlc.AfterCommit(r.Context(), wakeScheduler)
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusCreated),
+			Data: map[string]any{
+				"announcement": announcement,
+				"recipients":   len(recipients),
+			},
+		})
+	}
+}
+
+// ListAnnouncements returns the caller's announcements feed for a group, newest first. Pass the
+// returned next_cursor as ?before= to fetch the following page.
+func ListAnnouncements(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		limit = min(cmp.Or(max(limit, 0), defaultLimit), maxLimit)
+
+		var before pgtype.Int8
+		if cursor := r.URL.Query().Get("before"); cursor != "" {
+			id, err := strconv.ParseInt(cursor, 10, 64)
+			if err != nil {
+				return middleware.Error(fmt.Errorf("%w: invalid cursor %q", internal.ErrInvalidRequest, cursor))
+			}
+			before = pgtype.Int8{Int64: id, Valid: true}
+		}
+
+		member, err := groups.FindMember(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		announcements, err := store.ListMemberAnnouncements(r.Context(), sqlc.ListMemberAnnouncementsParams{
+			GroupID: groupID,
+			UserID:  member.UserID,
+			Before:  before,
+			Limit:   int32(limit),
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing announcements: %w", err))
+		}
+
+		var nextCursor *int64
+		if len(announcements) == limit {
+			nextCursor = &announcements[len(announcements)-1].ID
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data: map[string]any{
+				"announcements": announcements,
+				"next_cursor":   nextCursor,
+			},
+		})
+	}
+}
+
+// MarkAnnouncementRead marks the caller's in-app delivery of an announcement as read.
+func MarkAnnouncementRead(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		announcementID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		if _, err := store.MarkAnnouncementRead(r.Context(), sqlc.MarkAnnouncementReadParams{
+			AnnouncementID: announcementID,
+			UserID:         userID,
+		}); err != nil {
+			return middleware.Error(fmt.Errorf("marking announcement read: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{Message: http.StatusText(http.StatusOK)})
+	}
+}
+
+// ListDeliveries returns the per-recipient delivery status of an announcement to the group admins.
+func ListDeliveries(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		announcementID, err := internal.PathID(r, "announcementID")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		announcement, err := store.GetAnnouncement(r.Context(), announcementID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && announcement.GroupID != groupID) {
+			return middleware.Error(fmt.Errorf("announcement %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting announcement: %w", err))
+		}
+
+		deliveries, err := store.ListAnnouncementDeliveries(r.Context(), announcementID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing deliveries: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    deliveries,
+		})
+	}
+}
+
+// wake is signalled when an announcement has deliveries due right away, so DeliverScheduled sends them
+// without waiting for its next check.
+var wake = make(chan struct{}, 1)
+
+func wakeScheduler() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverScheduled sends the deliveries once they are due, checking every interval and whenever an
+// announcement is made until ctx is cancelled. Due deliveries are claimed first, so each is sent by one
+// instance only, and those an instance stopped sending are claimed again once its claim expires.
+func DeliverScheduled(ctx context.Context, store *sqlc.Store, dispatcher *notifications.Dispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		due, err := store.ClaimDueAnnouncementDeliveries(ctx, sqlc.ClaimDueAnnouncementDeliveriesParams{
+			LeaseUntil: pgtype.Timestamp{Time: time.Now().UTC().Add(deliveryTimeout), Valid: true},
+			Limit:      dueBatchSize,
+		})
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "announcements", "message", "claiming due deliveries", "error", err)
+		}
+
+		deliverDue(ctx, store, dispatcher, due)
+
+		// A full batch leaves more deliveries due, such as those of an announcement to a large group.
+		if len(due) == dueBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// deliverDue sends the claimed deliveries that the current preferences of their recipients still allow.
+// Recipients may have opted out or moved their quiet hours since the announcement was made, so deliveries
+// they opted out of are skipped and those falling within their quiet hours again are held back.
+func deliverDue(ctx context.Context, store *sqlc.Store, dispatcher *notifications.Dispatcher, due []sqlc.ClaimDueAnnouncementDeliveriesRow) {
+	userIDs := make(map[int64][]pgtype.UUID)
+	for _, delivery := range due {
+		userIDs[delivery.GroupID] = append(userIDs[delivery.GroupID], delivery.UserID)
+	}
+
+	groupPreferences := make(map[int64]map[pgtype.UUID]notifications.Preferences, len(userIDs))
+	for groupID, ids := range userIDs {
+		userPreferences, err := preferences.Load(ctx, store.Queries, pgtype.Int8{Int64: groupID, Valid: true}, ids)
+		if err != nil {
+			// The deliveries are claimed again once their claim expires.
+			slog.ErrorContext(ctx, "announcements", "message", "loading preferences", "groupID", groupID, "error", err)
+			continue
+		}
+		groupPreferences[groupID] = userPreferences
+	}
+
+	now := time.Now()
+	for _, delivery := range due {
+		userPreferences, ok := groupPreferences[delivery.GroupID]
+		if !ok {
+			continue
+		}
+		p := userPreferences[delivery.UserID]
+		channel := notifications.Channel(delivery.Channel)
+
+		if !p.Allows(notifications.Announcements, channel) {
+			if err := store.UpdateAnnouncementDelivery(ctx, sqlc.UpdateAnnouncementDeliveryParams{
+				ID:     delivery.ID,
+				Status: "skipped",
+				Error:  pgtype.Text{String: "recipient opted out", Valid: true},
+			}); err != nil {
+				slog.ErrorContext(ctx, "announcements", "message", "updating delivery status", "deliveryID", delivery.ID, "error", err)
+			}
+			continue
+		}
+
+		if after := p.DeliverAfter(channel, now); !after.IsZero() {
+			if err := store.RescheduleAnnouncementDelivery(ctx, sqlc.RescheduleAnnouncementDeliveryParams{
+				ID:           delivery.ID,
+				DeliverAfter: pgtype.Timestamp{Time: after.UTC(), Valid: true},
+			}); err != nil {
+				slog.ErrorContext(ctx, "announcements", "message", "rescheduling delivery", "deliveryID", delivery.ID, "error", err)
+			}
+			continue
+		}
+
+		recipient := notifications.Recipient{
+			MemberID: delivery.MemberID,
+			Name:     delivery.Name,
+			Email:    delivery.Email.String,
+			Phone:    cmp.Or(delivery.PhoneE164.String, delivery.Phone),
+		}
+		message := notifications.Message{Subject: delivery.Title, Body: delivery.Body}
+
+		send(ctx, store, dispatcher, delivery.ID, channel, recipient, message)
+	}
+}
+
+// send delivers a message over channel and records the outcome on the delivery.
+func send(
+	ctx context.Context,
+	store *sqlc.Store,
+	dispatcher *notifications.Dispatcher,
+	deliveryID int64,
+	channel notifications.Channel,
+	recipient notifications.Recipient,
+	message notifications.Message,
+) {
+	status := "sent"
+	var deliveryErr pgtype.Text
+
+	err := dispatcher.Send(ctx, channel, recipient, message)
+	switch {
+	case errors.Is(err, notifications.ErrNoAddress), errors.Is(err, notifications.ErrNoProvider):
+		status = "skipped"
+		deliveryErr = pgtype.Text{String: err.Error(), Valid: true}
+	case err != nil:
+		status = "failed"
+		deliveryErr = pgtype.Text{String: err.Error(), Valid: true}
+	}
+
+	if err := store.UpdateAnnouncementDelivery(ctx, sqlc.UpdateAnnouncementDeliveryParams{
+		ID:     deliveryID,
+		Status: status,
+		Error:  deliveryErr,
+	}); err != nil {
+		slog.ErrorContext(ctx, "announcements", "message", "updating delivery status", "deliveryID", deliveryID, "error", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package apikeys provides handlers for managing the group-scoped API keys partner systems such as
+// ticketing kiosks and CRM syncs authenticate with.
+package apikeys
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// CreateAPIKey issues an API key for a group with the given scopes and optional expiry. The key is only
+// returned in this response; afterwards only its prefix can be seen. Admins only.
+func CreateAPIKey(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Name      string     `json:"name" zog:"name"`
+			Scopes    []string   `json:"scopes" zog:"scopes"`
+			ExpiresAt *time.Time `json:"expires_at" zog:"expires_at"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"Name": zog.String().Required(zog.Message("Name is required")).
+				TestFunc(func(name *string, ctx zog.Ctx) bool {
+					return strings.TrimSpace(*name) != ""
+				}, zog.Message("Name is required")).
+				Max(100, zog.Message("Name must be at most 100 characters")),
+			"Scopes": zog.Slice(zog.String().OneOf(middleware.Scopes,
+				zog.Message("Scopes must be among "+strings.Join(middleware.Scopes, ", ")))).
+				Min(1, zog.Message("At least one scope is required")),
+			"ExpiresAt": zog.Ptr(zog.Time().TestFunc(func(t *time.Time, ctx zog.Ctx) bool {
+				return t.After(time.Now())
+			}, zog.Message("Expiry must be in the future"))),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating API key: %w", err))
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		key, prefix, hash, err := internal.GenerateAPIKey()
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		var expiresAt pgtype.Timestamp
+		if body.ExpiresAt != nil {
+			expiresAt = pgtype.Timestamp{Time: body.ExpiresAt.UTC(), Valid: true}
+		}
+
+		scopes := slices.Clone(body.Scopes)
+		slices.Sort(scopes)
+
+		apiKey, err := store.CreateApiKey(r.Context(), sqlc.CreateApiKeyParams{
+			GroupID:    groupID,
+			Name:       strings.TrimSpace(body.Name),
+			Prefix:     prefix,
+			SecretHash: hash,
+			Scopes:     slices.Compact(scopes),
+			CreatedBy:  userID,
+			ExpiresAt:  expiresAt,
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("creating API key: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusCreated),
+			Data: map[string]any{
+				"api_key": apiKey,
+				"key":     key,
+			},
+		})
+	}
+}
+
+// ListAPIKeys returns every API key of a group, newest first, including revoked ones. Admins only.
+func ListAPIKeys(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		apiKeys, err := store.ListGroupApiKeys(r.Context(), groupID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing API keys: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    apiKeys,
+		})
+	}
+}
+
+// RevokeAPIKey permanently disables an API key of a group. Admins only.
+func RevokeAPIKey(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		keyID, err := internal.PathID(r, "keyID")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		apiKey, err := store.RevokeApiKey(r.Context(), sqlc.RevokeApiKeyParams{
+			ID:      keyID,
+			GroupID: groupID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("API key %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("revoking API key: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    apiKey,
+		})
+	}
+}
@@ -12,11 +12,20 @@ import (
 	"runtime"
 	"syscall"
 	"time"
+	_ "time/tzdata" // group time zones must resolve in the alpine image, which ships without zoneinfo
 
+	"github.com/ship-labs/meet-loop-api/announcements"
 	"github.com/ship-labs/meet-loop-api/config"
 	"github.com/ship-labs/meet-loop-api/database"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/health"
 	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/members"
+	"github.com/ship-labs/meet-loop-api/metrics"
 	"github.com/ship-labs/meet-loop-api/middleware"
+	"github.com/ship-labs/meet-loop-api/notifications"
+	"github.com/ship-labs/meet-loop-api/telemetry"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
@@ -24,7 +33,7 @@ func main() {
 		context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP)
 	defer cancel()
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger := slog.New(middleware.NewContextHandler(slog.NewJSONHandler(os.Stdout, nil)))
 	logger = logger.With("app", "MeetLoop")
 	slog.SetDefault(logger)
 
@@ -36,19 +45,57 @@ func main() {
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
+	shutdownTracing, err := telemetry.Setup(timeoutCtx, cfg)
+	if err != nil {
+		exit(err, "telemetry.Setup")
+	}
+
 	conn, err := database.Dial(timeoutCtx, cfg.DBURL)
 	if err != nil {
 		exit(err, "database.Dial")
 	}
 	defer conn.Close()
+	metrics.RegisterPool(conn)
 	slog.InfoContext(ctx, "main", "message", "Connected to database successfully")
 
 	port := cmp.Or(cfg.Port, config.DefaultPort)
 	store := sqlc.NewStore(conn)
-	mux := defineRoutes(store)
 
-	handler := middleware.CorsMiddleware(mux)
+	// Revocations made before the start must apply to the first requests already.
+	denylist := middleware.NewDenylist(store, cfg.SessionRevocationRetention())
+	if err := denylist.Load(timeoutCtx); err != nil {
+		exit(err, "denylist.Load")
+	}
+	middleware.UseDenylist(denylist)
+
+	dispatcher := notifications.NewDispatcher()
+
+	var limits middleware.RateLimitStore = middleware.NewMemoryRateLimitStore()
+	if cfg.RateLimitStore == config.PostgresRateLimitStore {
+		postgresLimits := middleware.NewPostgresRateLimitStore(store)
+		go postgresLimits.Run(ctx, time.Hour)
+		limits = postgresLimits
+	}
+	limiter := middleware.NewRateLimiter(limits, cfg.TrustedProxyList())
+
+	probe, err := health.NewProbe(store)
+	if err != nil {
+		exit(err, "health.NewProbe")
+	}
+
+	mux := defineRoutes(cfg, store, dispatcher, limiter, probe)
+
+	go groups.Purge(ctx, store, cfg.GroupPurgeGracePeriod(), time.Hour)
+	go members.NormalizePhones(ctx, store, cfg.DefaultPhoneRegion)
+	go announcements.DeliverScheduled(ctx, store, dispatcher, time.Minute)
+	go denylist.Run(ctx, middleware.DefaultDenylistRefreshInterval)
+
+	handler := middleware.CorsMiddleware(metrics.Instrument(middleware.TraceRoutes(mux)))
 	handler = middleware.LoggingMiddleware(handler)
+	handler = middleware.RequestContext(handler)
+	handler = otelhttp.NewHandler(handler, telemetry.ServiceName, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method
+	}))
 	server := http.Server{
 		Handler: handler,
 		Addr:    fmt.Sprintf(":%d", port),
@@ -61,9 +108,30 @@ func main() {
 		}
 	}()
 
+	// Metrics are served on an admin port of their own, which is not exposed publicly.
+	adminMux := http.NewServeMux()
+	adminMux.Handle("GET /metrics", metrics.Handler())
+	adminServer := http.Server{
+		Handler: adminMux,
+		Addr:    fmt.Sprintf(":%d", cmp.Or(cfg.MetricsPort, config.DefaultMetricsPort)),
+	}
+
+	go func() {
+		err := adminServer.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			exit(err, "adminServer.ListenAndServe")
+		}
+	}()
+
 	slog.Info("main", "message", "Server started successfully", "port", server.Addr, "numCPUS", runtime.NumCPU())
 
 	<-ctx.Done()
+
+	// Readiness fails first, so load balancers stop routing requests here while those in flight finish.
+	probe.Drain()
+	slog.Info("main", "message", "Draining", "delay", cfg.ShutdownDrain().String())
+	time.Sleep(cfg.ShutdownDrain())
+
 	timeoutCtx, cancel = context.WithTimeout(context.Background(), time.Second*15)
 	defer cancel()
 
@@ -71,6 +139,14 @@ func main() {
 		exit(err, "server.Shutdown")
 	}
 
+	if err := adminServer.Shutdown(timeoutCtx); err != nil {
+		exit(err, "adminServer.Shutdown")
+	}
+
+	if err := shutdownTracing(timeoutCtx); err != nil {
+		slog.Error("main", "message", "flushing spans", "error", err)
+	}
+
 	slog.Info("main", "message", "Server shutdown successfully")
 }
 
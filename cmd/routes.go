@@ -2,27 +2,116 @@ package main
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/ship-labs/meet-loop-api/announcements"
+	"github.com/ship-labs/meet-loop-api/apikeys"
+	"github.com/ship-labs/meet-loop-api/config"
+	"github.com/ship-labs/meet-loop-api/events"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/health"
 	"github.com/ship-labs/meet-loop-api/internal"
 	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
 	"github.com/ship-labs/meet-loop-api/members"
 	"github.com/ship-labs/meet-loop-api/middleware"
+	"github.com/ship-labs/meet-loop-api/notifications"
+	"github.com/ship-labs/meet-loop-api/preferences"
+	"github.com/ship-labs/meet-loop-api/privacy"
+	"github.com/ship-labs/meet-loop-api/profiles"
+	"github.com/ship-labs/meet-loop-api/sessions"
 )
 
-func defineRoutes(store *sqlc.Store) *http.ServeMux {
+func defineRoutes(cfg config.Config, store *sqlc.Store, dispatcher *notifications.Dispatcher, limiter *middleware.RateLimiter, probe *health.Probe) *http.ServeMux {
 	mux := http.NewServeMux()
 
+	// Queries of signed in users run as their own role under row-level security when it is enabled.
+	rls := func(next middleware.Handler) middleware.Handler { return next }
+	if cfg.DBRowLevelSecurity {
+		rls = func(next middleware.Handler) middleware.Handler { return middleware.RowLevelSecurity(store, next) }
+	}
+	auth := func(next middleware.Handler) middleware.Handler { return middleware.Auth(rls(next)) }
+
+	// Sensitive operations take a recent multi-factor authentication.
+	stepUp := func(next middleware.Handler) middleware.Handler {
+		return middleware.RequireStepUp(cfg.StepUpMaxAge(), next)
+	}
+
+	// Endpoints that create content or send messages are limited per user, API key or client IP.
+	var (
+		createGroupLimit  = middleware.Limit{Requests: 10, Per: time.Hour}
+		rsvpLimit         = middleware.Limit{Requests: 30, Per: time.Minute}
+		claimLimit        = middleware.Limit{Requests: 10, Per: time.Minute}
+		announcementLimit = middleware.Limit{Requests: 20, Per: time.Hour}
+		apiKeyLimit       = middleware.Limit{Requests: 10, Per: time.Hour}
+		// clientIPLimit is shared by everyone behind the same address, so it only stops floods.
+		clientIPLimit = middleware.Limit{Requests: 120, Per: time.Minute}
+	)
+
+	// Limited endpoints are limited per client IP too before the token is verified, so requests without a
+	// valid token cannot flood them.
+	perIP := func(next middleware.Handler) middleware.Handler { return limiter.LimitIP(clientIPLimit, next) }
+
 	mux.Handle("GET /{$}", middleware.Auth(func(w http.ResponseWriter, r *http.Request) middleware.Handler {
 		return middleware.JSON(middleware.Response{Message: http.StatusText(http.StatusOK)})
 	}))
 
+	mux.Handle(internal.Liveness, health.Live())
+	mux.Handle(internal.Readiness, probe.Ready())
+
 	mux.Handle(internal.APIVersion, middleware.JSON(middleware.Response{
 		Message: http.StatusText(http.StatusOK),
 		Data:    "Welcome to MeetLoop API v1",
 	}))
 
-	mux.Handle(internal.Group, middleware.Auth(members.CreateGroup(store)))
-	mux.Handle(internal.Profile, middleware.Auth(members.GetUserProfile(store)))
+	mux.Handle(internal.Group, perIP(auth(limiter.Limit(createGroupLimit, members.CreateGroup(store, cfg.DefaultPhoneRegion)))))
+	mux.Handle(internal.Profile, auth(members.GetUserProfile(store)))
+	mux.Handle(internal.UpdateProfile, auth(profiles.UpdateProfile(store, cfg.DefaultPhoneRegion)))
+	mux.Handle(internal.MyGroups, auth(members.ListMyGroups(store)))
+	mux.Handle(internal.ExportData, auth(stepUp(privacy.ExportData(store))))
+	mux.Handle(internal.EraseAccount, auth(stepUp(privacy.EraseAccount(store))))
+
+	mux.Handle(internal.Groups, middleware.AllowAnonymous(auth(groups.ListGroups(store))))
+	mux.Handle(internal.GroupDetails, middleware.AllowAnonymous(auth(groups.GetGroup(store))))
+	mux.Handle(internal.GroupCategory, auth(middleware.GroupAdmin(store, groups.SetGroupCategory(store))))
+	mux.Handle(internal.GroupTags, auth(middleware.GroupAdmin(store, groups.SetGroupTags(store))))
+	mux.Handle(internal.GroupParent, auth(middleware.GroupAdmin(store, groups.SetGroupParent(store))))
+	mux.Handle(internal.GroupChapters, middleware.AllowAnonymous(auth(groups.ListChapters(store))))
+	mux.Handle(internal.GroupEvents, middleware.AllowAnonymous(middleware.AuthOrAPIKey(store, middleware.ScopeEventsRead, rls(groups.ListGroupEvents(store)))))
+	mux.Handle(internal.GroupMembers, middleware.AuthOrAPIKey(store, middleware.ScopeMembersRead, rls(middleware.GroupAdmin(store, groups.ListGroupMembers(store)))))
+	mux.Handle(internal.GroupAnalytics, auth(middleware.GroupAdmin(store, groups.Analytics(store))))
+	mux.Handle(internal.GroupTimeZone, auth(middleware.GroupAdmin(store, groups.SetGroupTimeZone(store))))
+	mux.Handle(internal.ArchiveGroup, auth(middleware.GroupAdmin(store, groups.ArchiveGroup(store))))
+	mux.Handle(internal.RestoreGroup, auth(groups.RestoreGroup(store, cfg.GroupPurgeGracePeriod())))
+	mux.Handle(internal.DeleteGroup, auth(stepUp(middleware.GroupAdmin(store, groups.DeleteGroup(store)))))
+	mux.Handle(internal.MergeGroup, auth(stepUp(groups.MergeGroups(store))))
+
+	// CreateRsvp checks membership itself, as anonymous sessions join the group as guests on their first RSVP.
+	mux.Handle(internal.CreateRsvp, middleware.AllowAnonymous(perIP(auth(limiter.Limit(rsvpLimit, events.CreateRsvp(store))))))
+	mux.Handle(internal.CancelRsvp, middleware.AllowAnonymous(perIP(auth(limiter.Limit(rsvpLimit, middleware.GroupMember(store, events.CancelRsvp(store)))))))
+	mux.Handle(internal.EventAttendees, middleware.AuthOrAPIKey(store, middleware.ScopeRsvpsRead, rls(middleware.GroupAdmin(store, events.ListAttendees(store)))))
+	mux.Handle(internal.RsvpAttendance, middleware.AuthOrAPIKey(store, middleware.ScopeRsvpsWrite, rls(middleware.GroupAdmin(store, events.SetAttendance(store)))))
+	mux.Handle(internal.ReliabilityThreshold, auth(middleware.GroupAdmin(store, events.SetReliabilityThreshold(store))))
+	// Claims move rows of another user, which row-level security rightly forbids.
+	mux.Handle(internal.ClaimAnonymousRsvps, perIP(middleware.Auth(limiter.Limit(claimLimit, events.ClaimAnonymousRsvps(store)))))
+
+	mux.Handle(internal.CreateAnnouncement, perIP(auth(limiter.Limit(announcementLimit, middleware.GroupAdmin(store, announcements.CreateAnnouncement(store, dispatcher))))))
+	mux.Handle(internal.Announcements, auth(middleware.GroupMember(store, announcements.ListAnnouncements(store))))
+	mux.Handle(internal.AnnouncementDeliveries, auth(middleware.GroupAdmin(store, announcements.ListDeliveries(store))))
+	mux.Handle(internal.AnnouncementRead, auth(announcements.MarkAnnouncementRead(store)))
+	mux.Handle(internal.Categories, middleware.AllowAnonymous(auth(groups.ListCategories(store))))
+	mux.Handle(internal.PopularTags, middleware.AllowAnonymous(auth(groups.PopularTags(store))))
+
+	mux.Handle(internal.NotificationPreferences, auth(preferences.GetPreferences(store)))
+	mux.Handle(internal.UpdateNotificationPreferences, auth(preferences.UpdatePreferences(store)))
+	mux.Handle(internal.GroupNotificationPreferences, auth(middleware.GroupMember(store, preferences.GetGroupPreferences(store))))
+	mux.Handle(internal.UpdateGroupNotificationPreferences, auth(middleware.GroupMember(store, preferences.UpdateGroupPreferences(store))))
+
+	mux.Handle(internal.APIKeys, auth(middleware.GroupAdmin(store, apikeys.ListAPIKeys(store))))
+	mux.Handle(internal.CreateAPIKey, perIP(auth(stepUp(limiter.Limit(apiKeyLimit, middleware.GroupAdmin(store, apikeys.CreateAPIKey(store)))))))
+	mux.Handle(internal.RevokeAPIKey, auth(middleware.GroupAdmin(store, apikeys.RevokeAPIKey(store))))
+
+	// Session revocations are hidden from user roles by row-level security.
+	mux.Handle(internal.RevokeSessions, middleware.Auth(sessions.RevokeSessions(store)))
 
 	return mux
 }
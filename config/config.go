@@ -3,12 +3,16 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	z "github.com/Oudwins/zog"
 	"github.com/Oudwins/zog/zenv"
 	"github.com/joho/godotenv"
+	"github.com/nyaruka/phonenumbers"
 	"github.com/ship-labs/meet-loop-api/internal"
 )
 
@@ -27,12 +31,70 @@ type Config struct {
 	JwtSecret          string `env:"JWT_SECRET" zog:"JwtSecret"`
 	SupabaseProjectURL string `env:"SUPABASE_PROJECT_URL" zog:"SupabaseProjectURL"`
 	SupabaseAPIKey     string `env:"SUPABASE_API_KEY" zog:"SupabaseAPIKey"`
+	// JWKSURL is where the public keys of asymmetrically signed tokens are published. It defaults to the
+	// Supabase project's well-known JWKS endpoint.
+	JWKSURL string `env:"JWKS_URL" zog:"JWKSURL"`
+	// JWTIssuers is a comma separated list of accepted token issuers. It defaults to the Supabase project's
+	// auth server.
+	JWTIssuers  string `env:"JWT_ISSUERS" zog:"JWTIssuers"`
+	JWTAudience string `env:"JWT_AUDIENCE" zog:"JWTAudience"`
+	// JWTAllowedRoles is a comma separated list of the role claims accepted on user requests.
+	JWTAllowedRoles string `env:"JWT_ALLOWED_ROLES" zog:"JWTAllowedRoles"`
+	// JWTLeewaySeconds is the clock skew tolerated when checking token expiry.
+	JWTLeewaySeconds int `env:"JWT_LEEWAY_SECONDS" zog:"JWTLeewaySeconds"`
+	// GroupPurgeGraceDays is how long a soft deleted group can be restored before it is purged.
+	GroupPurgeGraceDays int `env:"GROUP_PURGE_GRACE_DAYS" zog:"GroupPurgeGraceDays"`
+	// DefaultPhoneRegion is the ISO 3166-1 region assumed for phone numbers written without a country code.
+	DefaultPhoneRegion string `env:"DEFAULT_PHONE_REGION" zog:"DefaultPhoneRegion"`
+	// DBRowLevelSecurity runs the queries of signed in users as their own role so the database's
+	// row-level security policies apply to them.
+	DBRowLevelSecurity bool `env:"DB_ROW_LEVEL_SECURITY" zog:"DBRowLevelSecurity"`
+	// StepUpMaxAgeSeconds is how recently users must have authenticated, with a second factor, to perform
+	// sensitive operations.
+	StepUpMaxAgeSeconds int `env:"STEP_UP_MAX_AGE_SECONDS" zog:"StepUpMaxAgeSeconds"`
+	// JWTMaxLifetimeSeconds is the longest time an access token can be valid for, which is how long session
+	// revocations have to be kept to reject every token they cover.
+	JWTMaxLifetimeSeconds int `env:"JWT_MAX_LIFETIME_SECONDS" zog:"JWTMaxLifetimeSeconds"`
+	// RateLimitStore is where rate limits are counted: in memory, per instance, or in postgres, shared by
+	// every instance.
+	RateLimitStore string `env:"RATE_LIMIT_STORE" zog:"RateLimitStore"`
+	// TrustedProxies is a comma separated list of the addresses or CIDR ranges of the proxies whose
+	// X-Forwarded-For entries are believed when telling clients apart.
+	TrustedProxies string `env:"TRUSTED_PROXIES" zog:"TrustedProxies"`
+	// TracingExporter is where OpenTelemetry spans are exported to: nowhere, stdout or an OTLP collector.
+	TracingExporter string `env:"TRACING_EXPORTER" zog:"TracingExporter"`
+	// TracingEndpoint is the URL OTLP spans are posted to, such as http://localhost:4318/v1/traces. The
+	// standard OTEL_EXPORTER_OTLP_* variables apply when it is empty.
+	TracingEndpoint string `env:"TRACING_ENDPOINT" zog:"TracingEndpoint"`
+	// TracingSampleRatio is the share of the traces started by the API that are sampled. Requests carrying
+	// a traceparent follow the sampling decision of their caller.
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" zog:"TracingSampleRatio"`
+	// MetricsPort is the admin port Prometheus metrics are served on, apart from the public API.
+	MetricsPort int `env:"METRICS_PORT" zog:"MetricsPort"`
+	// ShutdownDrainSeconds is how long the server keeps serving after readiness starts failing on shutdown,
+	// so load balancers stop routing requests to it before it closes its listener.
+	ShutdownDrainSeconds int `env:"SHUTDOWN_DRAIN_SECONDS" zog:"ShutdownDrainSeconds"`
 }
 
 const (
-	DefaultPort     = 8080
-	DevEnvironment  = "development"
-	ProdEnvironment = "production"
+	DefaultPort                  = 8080
+	DefaultGroupPurgeGraceDays   = 30
+	DefaultPhoneRegion           = "US"
+	DefaultJWTAudience           = "authenticated"
+	DefaultJWTAllowedRoles       = "authenticated"
+	DefaultJWTLeewaySeconds      = 30
+	DefaultStepUpMaxAgeSeconds   = 300
+	DefaultJWTMaxLifetimeSeconds = 3600
+	MemoryRateLimitStore         = "memory"
+	PostgresRateLimitStore       = "postgres"
+	NoTracingExporter            = "none"
+	StdoutTracingExporter        = "stdout"
+	OTLPTracingExporter          = "otlp"
+	DefaultTracingSampleRatio    = 0.1
+	DefaultMetricsPort           = 9090
+	DefaultShutdownDrainSeconds  = 5
+	DevEnvironment               = "development"
+	ProdEnvironment              = "production"
 )
 
 func loadConfig() (Config, error) {
@@ -43,14 +105,39 @@ func loadConfig() (Config, error) {
 	}
 
 	schema := z.Struct(z.Shape{
-		"Port":               z.Int().Default(DefaultPort),
-		"Env":                z.String().Required().OneOf([]string{DevEnvironment, ProdEnvironment}),
-		"FrontendURL":        z.String().URL().Required(),
-		"DBURL":              z.String().URL().Required(),
-		"DBPassword":         z.String().Required(),
-		"JwtSecret":          z.String().Required(),
-		"SupabaseProjectURL": z.String().URL().Required(),
-		"SupabaseAPIKey":     z.String().Required(),
+		"Port":                  z.Int().Default(DefaultPort),
+		"Env":                   z.String().Required().OneOf([]string{DevEnvironment, ProdEnvironment}),
+		"FrontendURL":           z.String().URL().Required(),
+		"DBURL":                 z.String().URL().Required(),
+		"DBPassword":            z.String().Required(),
+		"DBRowLevelSecurity":    z.Bool(),
+		"JwtSecret":             z.String(),
+		"JWKSURL":               z.String().URL(),
+		"JWTIssuers":            z.String(),
+		"JWTAudience":           z.String().Default(DefaultJWTAudience),
+		"JWTAllowedRoles":       z.String().Default(DefaultJWTAllowedRoles),
+		"JWTLeewaySeconds":      z.Int().GTE(0).Default(DefaultJWTLeewaySeconds),
+		"JWTMaxLifetimeSeconds": z.Int().GT(0).Default(DefaultJWTMaxLifetimeSeconds),
+		"SupabaseProjectURL":    z.String().URL().Required(),
+		"SupabaseAPIKey":        z.String().Required(),
+		"GroupPurgeGraceDays":   z.Int().GT(0).Default(DefaultGroupPurgeGraceDays),
+		"StepUpMaxAgeSeconds":   z.Int().GT(0).Default(DefaultStepUpMaxAgeSeconds),
+		"RateLimitStore": z.String().Default(MemoryRateLimitStore).
+			OneOf([]string{MemoryRateLimitStore, PostgresRateLimitStore}, z.Message("RATE_LIMIT_STORE must be memory or postgres")),
+		"TrustedProxies": z.String().TestFunc(func(proxies *string, ctx z.Ctx) bool {
+			_, err := parsePrefixes(*proxies)
+			return err == nil
+		}, z.Message("TRUSTED_PROXIES must be a comma separated list of IP addresses or CIDR ranges")),
+		"TracingExporter": z.String().Default(NoTracingExporter).
+			OneOf([]string{NoTracingExporter, StdoutTracingExporter, OTLPTracingExporter}, z.Message("TRACING_EXPORTER must be none, stdout or otlp")),
+		"TracingEndpoint":      z.String().URL(),
+		"TracingSampleRatio":   z.Float64().GTE(0).LTE(1).Default(DefaultTracingSampleRatio),
+		"MetricsPort":          z.Int().Default(DefaultMetricsPort),
+		"ShutdownDrainSeconds": z.Int().GTE(0).Default(DefaultShutdownDrainSeconds),
+		"DefaultPhoneRegion": z.String().Default(DefaultPhoneRegion).
+			TestFunc(func(region *string, ctx z.Ctx) bool {
+				return phonenumbers.GetSupportedRegions()[*region]
+			}, z.Message("DEFAULT_PHONE_REGION must be a supported ISO 3166-1 region code such as US")),
 	})
 
 	var c Config
@@ -63,6 +150,91 @@ func loadConfig() (Config, error) {
 	return c, nil
 }
 
+// GroupPurgeGracePeriod returns how long soft deleted groups are kept before they are purged.
+func (c Config) GroupPurgeGracePeriod() time.Duration {
+	return time.Duration(c.GroupPurgeGraceDays) * 24 * time.Hour
+}
+
+// JWKSEndpoint returns the URL of the JSON Web Key Set used to verify asymmetrically signed tokens.
+func (c Config) JWKSEndpoint() string {
+	if c.JWKSURL != "" {
+		return c.JWKSURL
+	}
+	return strings.TrimSuffix(c.SupabaseProjectURL, "/") + "/auth/v1/.well-known/jwks.json"
+}
+
+// JWTIssuerList returns the accepted token issuers.
+func (c Config) JWTIssuerList() []string {
+	if c.JWTIssuers == "" {
+		return []string{strings.TrimSuffix(c.SupabaseProjectURL, "/") + "/auth/v1"}
+	}
+	return splitList(c.JWTIssuers)
+}
+
+// JWTAllowedRoleList returns the role claims accepted on user requests.
+func (c Config) JWTAllowedRoleList() []string {
+	return splitList(c.JWTAllowedRoles)
+}
+
+// JWTLeeway returns the clock skew tolerated when checking token expiry.
+func (c Config) JWTLeeway() time.Duration {
+	return time.Duration(c.JWTLeewaySeconds) * time.Second
+}
+
+// StepUpMaxAge returns how recently users must have authenticated to perform sensitive operations.
+func (c Config) StepUpMaxAge() time.Duration {
+	return time.Duration(c.StepUpMaxAgeSeconds) * time.Second
+}
+
+// SessionRevocationRetention returns how long session revocations are kept: as long as the tokens they
+// reject can live, plus the clock skew tolerated on their expiry.
+func (c Config) SessionRevocationRetention() time.Duration {
+	return time.Duration(c.JWTMaxLifetimeSeconds)*time.Second + c.JWTLeeway()
+}
+
+// ShutdownDrain returns how long the server keeps serving once readiness fails on shutdown.
+func (c Config) ShutdownDrain() time.Duration {
+	return time.Duration(c.ShutdownDrainSeconds) * time.Second
+}
+
+// TrustedProxyList returns the ranges of the proxies whose X-Forwarded-For entries are believed.
+func (c Config) TrustedProxyList() []netip.Prefix {
+	prefixes, _ := parsePrefixes(c.TrustedProxies)
+	return prefixes
+}
+
+// parsePrefixes parses a comma separated list of CIDR ranges, where single addresses stand for themselves.
+func parsePrefixes(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range splitList(s) {
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func LoadConfig() (Config, error) {
 	once.Do(func() {
 		config, err = loadConfig()
@@ -4,12 +4,20 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func Dial(ctx context.Context, address string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(ctx, address)
+	config, err := pgxpool.ParseConfig(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing connection string %s: %w", address, err)
+	}
+	config.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithSpanNameFunc(querySpanName))
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("opening connection to %s: %w", address, err)
 	}
@@ -21,3 +29,16 @@ func Dial(ctx context.Context, address string) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// querySpanName names the span of a query after the sqlc query it runs, or else its first keyword, so
+// span names do not grow with every distinct statement.
+func querySpanName(sql string) string {
+	if name, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		if name, _, ok := strings.Cut(name, " "); ok {
+			return name
+		}
+	}
+
+	keyword, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	return strings.ToUpper(keyword)
+}
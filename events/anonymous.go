@@ -0,0 +1,98 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Oudwins/zog"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// ClaimAnonymousRsvps carries the RSVPs and guest memberships of an anonymous session over to the caller.
+// Users who link an identity to their anonymous session keep its user ID and need no claim; this is for
+// those who sign in to an existing account instead. The anonymous session's access token, which must still
+// be valid, proves it was theirs. Returns every RSVP of the caller.
+func ClaimAnonymousRsvps(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			AnonymousToken string `json:"anonymous_token" zog:"anonymous_token"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"AnonymousToken": zog.String().Required(zog.Message("Anonymous token is required")),
+		})
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating claim: %w", err))
+		}
+
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		claims, err := middleware.Verify(r.Context(), body.AnonymousToken)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("%w: anonymous token: %w", internal.ErrInvalidRequest, err))
+		}
+
+		if !claims.IsAnonymous {
+			return middleware.Error(fmt.Errorf("%w: token is not of an anonymous session", internal.ErrInvalidRequest))
+		}
+
+		anonymousID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("%w: parsing anonymous user ID %s: %w", internal.ErrInvalidRequest, claims.Subject, err))
+		}
+
+		params := sqlc.ClaimAnonymousRsvpsParams{
+			UserID:          userID,
+			AnonymousUserID: pgtype.UUID{Bytes: anonymousID, Valid: true},
+		}
+
+		if params.AnonymousUserID != userID {
+			err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+				if err := q.ClaimAnonymousRsvps(r.Context(), params); err != nil {
+					return fmt.Errorf("claiming rsvps: %w", err)
+				}
+
+				if err := q.ClaimAnonymousDeliveries(r.Context(), sqlc.ClaimAnonymousDeliveriesParams(params)); err != nil {
+					return fmt.Errorf("claiming announcement deliveries: %w", err)
+				}
+
+				if _, err := q.DeleteClaimedAnonymousMembers(r.Context(), sqlc.DeleteClaimedAnonymousMembersParams(params)); err != nil {
+					return fmt.Errorf("deleting claimed memberships: %w", err)
+				}
+
+				if _, err := q.ReassignAnonymousMembers(r.Context(), sqlc.ReassignAnonymousMembersParams(params)); err != nil {
+					return fmt.Errorf("reassigning memberships: %w", err)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return middleware.Error(fmt.Errorf("claiming anonymous session: %w", err))
+			}
+		}
+
+		rsvps, err := store.ListUserRsvps(r.Context(), userID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing rsvps: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    rsvps,
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+var outcomes = []string{"attended", "no_show", "late_cancellation"}
+
+// History is a member's attendance record along with the reliability score derived from it.
+type History struct {
+	RSVPs             int64 `json:"rsvps"`
+	Attended          int64 `json:"attended"`
+	NoShows           int64 `json:"no_shows"`
+	LateCancellations int64 `json:"late_cancellations"`
+	// Reliability is the share of recorded outcomes the member attended, or nil without any recorded outcome.
+	Reliability *float64 `json:"reliability"`
+}
+
+type Attendee struct {
+	RsvpID     int64            `json:"rsvp_id"`
+	MemberID   int64            `json:"member_id"`
+	Name       string           `json:"name"`
+	Attendance pgtype.Text      `json:"attendance"`
+	HasPaid    pgtype.Bool      `json:"has_paid"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	Group      History          `json:"group"`
+	Platform   History          `json:"platform"`
+}
+
+// ListAttendees returns the RSVPs to an event along with each member's attendance history and reliability
+// score, both in the group and across the platform. Admins only.
+func ListAttendees(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		eventID, err := internal.PathID(r, "eventID")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := groups.Find(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if _, err := findEvent(r.Context(), store, groupID, eventID); err != nil {
+			return middleware.Error(err)
+		}
+
+		rows, err := store.ListEventAttendees(r.Context(), eventID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing attendees: %w", err))
+		}
+
+		attendees := make([]Attendee, 0, len(rows))
+		for _, row := range rows {
+			attendees = append(attendees, Attendee{
+				RsvpID:     row.RsvpID,
+				MemberID:   row.MemberID,
+				Name:       row.Name,
+				Attendance: row.Attendance,
+				HasPaid:    row.HasPaid,
+				CreatedAt:  row.CreatedAt,
+				Group:      newHistory(row.Rsvps, row.Attended, row.NoShows, row.LateCancellations),
+				Platform: newHistory(row.PlatformRsvps, row.PlatformAttended, row.PlatformNoShows,
+					row.PlatformLateCancellations),
+			})
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data: map[string]any{
+				"min_reliability": group.MinReliability,
+				"attendees":       attendees,
+			},
+		})
+	}
+}
+
+// SetAttendance records whether a member attended, did not show up to or cancelled late for an event.
+// A null attendance clears the outcome. Cancelled RSVPs keep the outcome of their cancellation, so they
+// are not found. Admins only.
+func SetAttendance(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Attendance *string `json:"attendance" zog:"attendance"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"Attendance": zog.Ptr(zog.String().OneOf(outcomes,
+				zog.Message("Attendance must be one of attended, no_show or late_cancellation"))),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		rsvpID, err := internal.PathID(r, "rsvpID")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating attendance: %w", err))
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		var attendance pgtype.Text
+		if body.Attendance != nil {
+			attendance = pgtype.Text{String: *body.Attendance, Valid: true}
+		}
+
+		rsvp, err := store.SetRsvpAttendance(r.Context(), sqlc.SetRsvpAttendanceParams{
+			Attendance: attendance,
+			ID:         rsvpID,
+			GroupID:    groupID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("rsvp %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("setting attendance: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    rsvp,
+		})
+	}
+}
+
+// SetReliabilityThreshold sets the minimum reliability score, between 0 and 1, members need to RSVP to
+// the group's events. A null min_reliability lifts the restriction. Admins only.
+func SetReliabilityThreshold(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			MinReliability *float64 `json:"min_reliability" zog:"min_reliability"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"MinReliability": zog.Ptr(zog.Float64().
+				GTE(0, zog.Message("Minimum reliability must be at least 0")).
+				LTE(1, zog.Message("Minimum reliability must be at most 1"))),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating reliability threshold: %w", err))
+		}
+
+		if _, err := groups.FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := groups.RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		var minReliability pgtype.Float8
+		if body.MinReliability != nil {
+			minReliability = pgtype.Float8{Float64: *body.MinReliability, Valid: true}
+		}
+
+		group, err := store.SetGroupMinReliability(r.Context(), sqlc.SetGroupMinReliabilityParams{
+			ID:             groupID,
+			MinReliability: minReliability,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("group %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("setting reliability threshold: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
+
+func newHistory(rsvps, attended, noShows, lateCancellations int64) History {
+	history := History{
+		RSVPs:             rsvps,
+		Attended:          attended,
+		NoShows:           noShows,
+		LateCancellations: lateCancellations,
+	}
+
+	if recorded := attended + noShows + lateCancellations; recorded > 0 {
+		reliability := float64(attended) / float64(recorded)
+		history.Reliability = &reliability
+	}
+
+	return history
+}
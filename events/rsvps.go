@@ -0,0 +1,229 @@
+// Package events provides handlers for RSVPing to events and tracking attendance.
+package events
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/metrics"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+const (
+	// lateCancellationWindow is how close to the start of an event a cancellation counts against reliability.
+	lateCancellationWindow = 24 * time.Hour
+	// guestName is the member name of anonymous sessions that did not give one.
+	guestName = "Guest"
+)
+
+// CreateRsvp RSVPs the caller to an event of a group they are a member of. Groups with a minimum
+// reliability turn away members whose platform-wide score is below it; members without any recorded
+// attendance are always let through. Anonymous sessions may RSVP to free public events of any group,
+// which they join as a guest.
+func CreateRsvp(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		eventID, err := internal.PathID(r, "eventID")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := groups.FindActive(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		event, err := findEvent(r.Context(), store, groupID, eventID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		member, err := rsvpMember(r.Context(), store, event)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if group.MinReliability.Valid {
+			attendance, err := store.GetUserAttendance(r.Context(), member.UserID)
+			if err != nil {
+				return middleware.Error(fmt.Errorf("getting attendance history: %w", err))
+			}
+
+			history := newHistory(attendance.Rsvps, attendance.Attended, attendance.NoShows, attendance.LateCancellations)
+			if history.Reliability != nil && *history.Reliability < group.MinReliability.Float64 {
+				return middleware.Error(fmt.Errorf("%w: your reliability score of %.2f is below the %.2f this group requires",
+					internal.ErrForbidden, *history.Reliability, group.MinReliability.Float64))
+			}
+		}
+
+		rsvp, err := store.CreateRsvp(r.Context(), sqlc.CreateRsvpParams{
+			MemberID: member.ID,
+			EventID:  eventID,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == internal.UniqueViolationCode {
+				return middleware.Error(fmt.Errorf("rsvp %w", internal.ErrExists))
+			}
+			return middleware.Error(fmt.Errorf("creating rsvp: %w", err))
+		}
+
+		sqlc.AfterCommit(r.Context(), func() {
+			metrics.RsvpsCreated.WithLabelValues(strconv.FormatBool(event.IsPaid.Bool)).Inc()
+			if event.IsPaid.Bool {
+				metrics.Payments.WithLabelValues("pending").Inc()
+			}
+		})
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusCreated),
+			Data:    rsvp,
+		})
+	}
+}
+
+// CancelRsvp withdraws the caller's RSVP to an event. Cancelling less than a day before the event starts
+// is recorded as a late cancellation.
+func CancelRsvp(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		eventID, err := internal.PathID(r, "eventID")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		member, err := groups.FindMember(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		event, err := findEvent(r.Context(), store, groupID, eventID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		rsvp, err := store.GetMemberRsvp(r.Context(), sqlc.GetMemberRsvpParams{
+			MemberID: member.ID,
+			EventID:  eventID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("rsvp %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting rsvp: %w", err))
+		}
+
+		var attendance pgtype.Text
+		if event.StartsAt.Valid && time.Until(event.StartsAt.Time) < lateCancellationWindow {
+			attendance = pgtype.Text{String: "late_cancellation", Valid: true}
+		}
+
+		// A withdrawn RSVP to a paid event is owed a refund if it was paid, or its payment is abandoned.
+		payment := "abandoned"
+		if rsvp.HasPaid.Bool {
+			payment = "refund_due"
+		}
+
+		rsvp, err = store.CancelRsvp(r.Context(), sqlc.CancelRsvpParams{
+			Attendance: attendance,
+			ID:         rsvp.ID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("rsvp %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("cancelling rsvp: %w", err))
+		}
+
+		sqlc.AfterCommit(r.Context(), func() {
+			metrics.RsvpsCancelled.WithLabelValues(strconv.FormatBool(attendance.Valid)).Inc()
+			if event.IsPaid.Bool {
+				metrics.Payments.WithLabelValues(payment).Inc()
+			}
+		})
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    rsvp,
+		})
+	}
+}
+
+// rsvpMember returns the caller's membership of the group of the event they RSVP to. Anonymous sessions
+// are held to free public events, and join the group as a guest on their first RSVP to it.
+func rsvpMember(ctx context.Context, store *sqlc.Store, event sqlc.Event) (sqlc.Member, error) {
+	if !middleware.IsAnonymous(ctx) {
+		return groups.FindMember(ctx, store, event.GroupID)
+	}
+
+	if event.IsPaid.Bool || event.Visibility != groups.VisibilityPublic {
+		return sqlc.Member{}, middleware.UpgradeRequired("anonymous sessions can only RSVP to free public events")
+	}
+
+	member, err := groups.FindMember(ctx, store, event.GroupID)
+	if !errors.Is(err, internal.ErrForbidden) {
+		return member, err
+	}
+
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return member, fmt.Errorf("getting user ID: %w", err)
+	}
+
+	metadata, err := middleware.GetUserMetadata(ctx)
+	if err != nil {
+		return member, err
+	}
+
+	// Under row-level security the request runs in one transaction, so the join gets a savepoint of its own
+	// to keep a failed insert from aborting the rest of the request.
+	err = store.ExecuteTransaction(ctx, func(q *sqlc.Queries) error {
+		member, err = q.CreateGroupMember(ctx, sqlc.CreateGroupMemberParams{
+			GroupID: event.GroupID,
+			Name:    cmp.Or(metadata.Name, guestName),
+			UserID:  userID,
+		})
+		return err
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == internal.UniqueViolationCode {
+		// A concurrent RSVP of the same session joined first.
+		return groups.FindMember(ctx, store, event.GroupID)
+	}
+	if err != nil {
+		return member, fmt.Errorf("joining group as a guest: %w", err)
+	}
+
+	return member, nil
+}
+
+// findEvent loads an event, translating a missing row or an event of another group into internal.ErrNotExist.
+func findEvent(ctx context.Context, store *sqlc.Store, groupID, eventID int64) (sqlc.Event, error) {
+	event, err := store.GetEvent(ctx, eventID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && event.GroupID != groupID) {
+		return event, fmt.Errorf("event %w", internal.ErrNotExist)
+	}
+	if err != nil {
+		return event, fmt.Errorf("getting event %d: %w", eventID, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,158 @@
+package groups
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+const (
+	defaultInterval       = "week"
+	defaultAnalyticsRange = 90 * 24 * time.Hour
+	mostEngagedLimit      = 10
+)
+
+var intervals = []string{"day", "week", "month"}
+
+// Analytics returns member growth, event attendance, revenue and the most engaged members of a group.
+// Metrics are bucketed by ?interval= (day, week or month) in the group's time zone, starting from
+// ?from= (YYYY-MM-DD) or the last 90 days by default.
+func Analytics(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		interval := cmp.Or(r.URL.Query().Get("interval"), defaultInterval)
+		if !slices.Contains(intervals, interval) {
+			return middleware.Error(fmt.Errorf("%w: invalid interval %q", internal.ErrInvalidRequest, interval))
+		}
+
+		group, err := Find(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		location, err := time.LoadLocation(group.TimeZone)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("loading time zone %s: %w", group.TimeZone, err))
+		}
+
+		since := time.Now().Add(-defaultAnalyticsRange)
+		if from := r.URL.Query().Get("from"); from != "" {
+			since, err = time.ParseInLocation(time.DateOnly, from, location)
+			if err != nil {
+				return middleware.Error(fmt.Errorf("%w: invalid from date %q", internal.ErrInvalidRequest, from))
+			}
+		}
+		sinceTimestamp := pgtype.Timestamp{Time: since.UTC(), Valid: true}
+
+		growth, err := store.GetMemberGrowth(r.Context(), sqlc.GetMemberGrowthParams{
+			Bucket:   interval,
+			TimeZone: group.TimeZone,
+			GroupID:  groupID,
+			Since:    sinceTimestamp,
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting member growth: %w", err))
+		}
+
+		events, err := store.GetEventStats(r.Context(), sqlc.GetEventStatsParams{
+			Bucket:   interval,
+			TimeZone: group.TimeZone,
+			GroupID:  groupID,
+			Since:    sinceTimestamp,
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting event stats: %w", err))
+		}
+
+		engaged, err := store.GetMostEngagedMembers(r.Context(), sqlc.GetMostEngagedMembersParams{
+			GroupID:    groupID,
+			Since:      sinceTimestamp,
+			MaxResults: mostEngagedLimit,
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting most engaged members: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data: map[string]any{
+				"interval":      interval,
+				"time_zone":     group.TimeZone,
+				"since":         since.In(location),
+				"member_growth": growth,
+				"events":        events,
+				"most_engaged":  engaged,
+			},
+		})
+	}
+}
+
+// SetGroupTimeZone sets the IANA time zone used to bucket the group's analytics.
+func SetGroupTimeZone(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			TimeZone string `json:"time_zone" zog:"time_zone"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"TimeZone": zog.String().Trim().Required(zog.Message("Time zone is required")).
+				TestFunc(internal.ValidTimeZone, zog.Message("Time zone must be a valid IANA time zone such as Europe/London")),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating time zone: %w", err))
+		}
+
+		if _, err := FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := store.SetGroupTimeZone(r.Context(), sqlc.SetGroupTimeZoneParams{
+			ID:       groupID,
+			TimeZone: body.TimeZone,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("group %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("setting group time zone: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package groups
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// SetGroupParent attaches a group as a chapter of another group, or detaches it when parent_id is null.
+// Attaching requires admin rights over both groups so nobody can graft their group onto someone else's.
+func SetGroupParent(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			ParentID *int64 `json:"parent_id" zog:"parent_id"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"ParentID": zog.Ptr(zog.Int64().GT(0, zog.Message("Parent group ID must be positive"))),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating group parent: %w", err))
+		}
+
+		if _, err := FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		var parentID pgtype.Int8
+		if body.ParentID != nil {
+			if *body.ParentID == groupID {
+				return middleware.Error(fmt.Errorf("%w: a group cannot be its own chapter", internal.ErrConflict))
+			}
+
+			if _, err := Find(r.Context(), store, *body.ParentID); err != nil {
+				return middleware.Error(fmt.Errorf("parent %w", err))
+			}
+
+			if err := RequireAdmin(r.Context(), store, *body.ParentID); err != nil {
+				return middleware.Error(err)
+			}
+
+			parentID = pgtype.Int8{Int64: *body.ParentID, Valid: true}
+		}
+
+		group, err := store.SetGroupParent(r.Context(), sqlc.SetGroupParentParams{
+			ID:       groupID,
+			ParentID: parentID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("group %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == internal.CheckViolationCode {
+				return middleware.Error(fmt.Errorf("%w: a group cannot be a chapter of its own chapter", internal.ErrConflict))
+			}
+			return middleware.Error(fmt.Errorf("setting group parent: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
+
+// ListChapters returns every chapter below a group, at any depth.
+func ListChapters(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if _, err := Find(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		chapters, err := store.ListChapters(r.Context(), pgtype.Int8{Int64: groupID, Valid: true})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing chapters: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    chapters,
+		})
+	}
+}
+
+// Event visibilities. Anonymous sessions only see and RSVP to public events.
+const (
+	VisibilityPublic  = "public"
+	VisibilityMembers = "members"
+)
+
+// ListGroupEvents returns the events of a group together with the events of all of its chapters. Anonymous
+// sessions only get the public ones.
+func ListGroupEvents(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if _, err := Find(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		var visibility pgtype.Text
+		if middleware.IsAnonymous(r.Context()) {
+			visibility = pgtype.Text{String: VisibilityPublic, Valid: true}
+		}
+
+		limit, offset := pagination(r)
+		events, err := store.ListGroupTreeEvents(r.Context(), sqlc.ListGroupTreeEventsParams{
+			ID:         groupID,
+			Visibility: visibility,
+			Limit:      int32(limit),
+			Offset:     int32(offset),
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing group events: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    events,
+		})
+	}
+}
+
+// ListGroupMembers returns the members of a group together with the members of all of its chapters.
+// Only admins of the group, or of one of its parents, may list members.
+func ListGroupMembers(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if _, err := Find(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		limit, offset := pagination(r)
+		members, err := store.ListGroupTreeMembers(r.Context(), sqlc.ListGroupTreeMembersParams{
+			ID:     groupID,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing group members: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    members,
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package groups provides handlers for browsing and managing groups.
+package groups
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+func GetGroup(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := Find(r.Context(), store, groupID)
+		if errors.Is(err, internal.ErrNotExist) {
+			// Groups merged into another one keep resolving to the group that replaced them.
+			if target, err := store.GetGroupRedirect(r.Context(), groupID); err == nil {
+				return redirect(target)
+			}
+		}
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		var category *sqlc.Category
+		if group.CategoryID.Valid {
+			c, err := store.GetCategory(r.Context(), group.CategoryID.Int64)
+			if err != nil {
+				return middleware.Error(fmt.Errorf("getting group category: %w", err))
+			}
+			category = &c
+		}
+
+		tags, err := store.ListGroupTags(r.Context(), groupID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing group tags: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data: map[string]any{
+				"group":    group,
+				"category": category,
+				"tags":     tags,
+			},
+		})
+	}
+}
+
+func ListGroups(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		query := r.URL.Query()
+		limit, offset := pagination(r)
+
+		category := query.Get("category")
+		tag := normalizeTag(query.Get("tag"))
+
+		groups, err := store.ListGroups(r.Context(), sqlc.ListGroupsParams{
+			Category: pgtype.Text{String: category, Valid: category != ""},
+			Tag:      pgtype.Text{String: tag, Valid: tag != ""},
+			Limit:    int32(limit),
+			Offset:   int32(offset),
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing groups: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    groups,
+		})
+	}
+}
+
+// Find loads a group that has not been deleted, translating a missing row into internal.ErrNotExist.
+// The group loaded by the group middleware is reused when there is one.
+func Find(ctx context.Context, store *sqlc.Store, groupID int64) (sqlc.Group, error) {
+	if membership, ok := middleware.GetMembership(ctx, groupID); ok {
+		return membership.Group, nil
+	}
+
+	group, err := store.GetGroup(ctx, groupID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return group, fmt.Errorf("group %w", internal.ErrNotExist)
+	}
+	if err != nil {
+		return group, fmt.Errorf("getting group %d: %w", groupID, err)
+	}
+	return group, nil
+}
+
+// FindActive is like Find but returns internal.ErrArchived for archived groups, which are read-only.
+func FindActive(ctx context.Context, store *sqlc.Store, groupID int64) (sqlc.Group, error) {
+	group, err := Find(ctx, store, groupID)
+	if err != nil {
+		return group, err
+	}
+
+	if group.ArchivedAt.Valid {
+		return group, internal.ErrArchived
+	}
+
+	return group, nil
+}
+
+// RequireAdmin returns internal.ErrForbidden unless the caller administers the group or one of its parents.
+func RequireAdmin(ctx context.Context, store *sqlc.Store, groupID int64) error {
+	if membership, ok := middleware.GetMembership(ctx, groupID); ok {
+		if !membership.IsAdmin {
+			return internal.ErrForbidden
+		}
+		return nil
+	}
+
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return fmt.Errorf("getting user ID: %w", err)
+	}
+
+	isAdmin, err := store.IsUserGroupAdmin(ctx, sqlc.IsUserGroupAdminParams{
+		UserID:  userID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return fmt.Errorf("checking group admin: %w", err)
+	}
+
+	if !isAdmin {
+		return internal.ErrForbidden
+	}
+
+	return nil
+}
+
+// RequirePlatformAdmin returns internal.ErrForbidden unless the caller is a platform admin.
+func RequirePlatformAdmin(ctx context.Context, store *sqlc.Store) error {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return fmt.Errorf("getting user ID: %w", err)
+	}
+
+	isAdmin, err := store.IsPlatformAdmin(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("checking platform admin: %w", err)
+	}
+
+	if !isAdmin {
+		return internal.ErrForbidden
+	}
+
+	return nil
+}
+
+// FindMember returns the caller's membership of a group, or internal.ErrForbidden if they are not a member.
+func FindMember(ctx context.Context, store *sqlc.Store, groupID int64) (sqlc.Member, error) {
+	if membership, ok := middleware.GetMembership(ctx, groupID); ok {
+		if !membership.IsMember {
+			return membership.Member, internal.ErrForbidden
+		}
+		return membership.Member, nil
+	}
+
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return sqlc.Member{}, fmt.Errorf("getting user ID: %w", err)
+	}
+
+	member, err := store.GetGroupMember(ctx, sqlc.GetGroupMemberParams{
+		UserID:  userID,
+		GroupID: groupID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return member, internal.ErrForbidden
+	}
+	if err != nil {
+		return member, fmt.Errorf("getting group member: %w", err)
+	}
+
+	return member, nil
+}
+
+// redirect permanently redirects the client to the group that replaced the requested one.
+func redirect(groupID int64) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		w.Header().Set("Location", fmt.Sprintf("%s/groups/%d", internal.APIVersion, groupID))
+		return middleware.Code(http.StatusPermanentRedirect, middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusPermanentRedirect),
+			Data:    map[string]int64{"group_id": groupID},
+		}))
+	}
+}
+
+// pagination reads the limit and offset query parameters, falling back to sane defaults.
+func pagination(r *http.Request) (limit, offset int) {
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	limit = min(cmp.Or(max(limit, 0), defaultLimit), maxLimit)
+
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	return limit, max(offset, 0)
+}
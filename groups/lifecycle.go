@@ -0,0 +1,128 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// ArchiveGroup makes a group read-only. Members can still browse it, but no new members, events or RSVPs
+// are accepted until it is restored.
+func ArchiveGroup(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if _, err := FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := store.ArchiveGroup(r.Context(), groupID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(internal.ErrArchived)
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("archiving group: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
+
+// DeleteGroup soft deletes a group. It can be restored until the purge grace period runs out.
+func DeleteGroup(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := store.SoftDeleteGroup(r.Context(), groupID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("group %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("deleting group: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
+
+// RestoreGroup unarchives a group, or undeletes it if it was soft deleted less than grace ago.
+func RestoreGroup(store *sqlc.Store, grace time.Duration) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		group, err := store.RestoreGroup(r.Context(), sqlc.RestoreGroupParams{
+			ID:           groupID,
+			DeletedAfter: pgtype.Timestamp{Time: time.Now().UTC().Add(-grace), Valid: true},
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("group %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("restoring group: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
+
+// Purge hard deletes groups that were soft deleted more than grace ago, along with their members, events
+// and RSVPs, every interval until ctx is cancelled.
+func Purge(ctx context.Context, store *sqlc.Store, grace, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := store.PurgeDeletedGroups(ctx, pgtype.Timestamp{Time: time.Now().UTC().Add(-grace), Valid: true})
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "groups", "message", "purging deleted groups", "error", err)
+		}
+		if purged > 0 {
+			slog.InfoContext(ctx, "groups", "message", "purged deleted groups", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package groups
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// MergeGroups folds a duplicate group into target_group_id. Members, events, RSVPs, announcements, chapters
+// and admin roles move to the target in a single transaction; users who belong to both groups keep their
+// target membership. The source group is then soft deleted and redirects to the target. Platform admins only.
+func MergeGroups(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			TargetGroupID int64 `json:"target_group_id" zog:"target_group_id"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"TargetGroupID": zog.Int64().Required(zog.Message("Target group ID is required")).
+				GT(0, zog.Message("Target group ID must be positive")),
+		})
+
+		sourceID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating group merge: %w", err))
+		}
+
+		if err := RequirePlatformAdmin(r.Context(), store); err != nil {
+			return middleware.Error(err)
+		}
+
+		targetID := body.TargetGroupID
+		if targetID == sourceID {
+			return middleware.Error(fmt.Errorf("%w: a group cannot be merged into itself", internal.ErrConflict))
+		}
+
+		source, err := Find(r.Context(), store, sourceID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		target, err := FindActive(r.Context(), store, targetID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("target %w", err))
+		}
+
+		var duplicates, members, events int64
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			ids := sqlc.RemapDuplicateMemberRsvpsParams{TargetID: targetID, SourceID: sourceID}
+
+			if err := q.RemapDuplicateMemberRsvps(r.Context(), ids); err != nil {
+				return fmt.Errorf("remapping rsvps: %w", err)
+			}
+
+			if err := q.RemapDuplicateMemberDeliveries(r.Context(), sqlc.RemapDuplicateMemberDeliveriesParams(ids)); err != nil {
+				return fmt.Errorf("remapping announcement deliveries: %w", err)
+			}
+
+			if err := q.RemapDuplicateMemberAuthors(r.Context(), sqlc.RemapDuplicateMemberAuthorsParams(ids)); err != nil {
+				return fmt.Errorf("remapping announcement authors: %w", err)
+			}
+
+			if err := q.MergeGroupAdmins(r.Context(), sqlc.MergeGroupAdminsParams(ids)); err != nil {
+				return fmt.Errorf("merging group admins: %w", err)
+			}
+
+			if err := q.DeleteGroupAdmins(r.Context(), sourceID); err != nil {
+				return fmt.Errorf("deleting source group admins: %w", err)
+			}
+
+			duplicates, err = q.DeleteDuplicateMembers(r.Context(), sqlc.DeleteDuplicateMembersParams{
+				SourceID: sourceID,
+				TargetID: targetID,
+			})
+			if err != nil {
+				return fmt.Errorf("deleting duplicate members: %w", err)
+			}
+
+			members, err = q.MoveGroupMembers(r.Context(), sqlc.MoveGroupMembersParams(ids))
+			if err != nil {
+				return fmt.Errorf("moving members: %w", err)
+			}
+
+			events, err = q.MoveGroupEvents(r.Context(), sqlc.MoveGroupEventsParams(ids))
+			if err != nil {
+				return fmt.Errorf("moving events: %w", err)
+			}
+
+			if err := q.MoveGroupAnnouncements(r.Context(), sqlc.MoveGroupAnnouncementsParams(ids)); err != nil {
+				return fmt.Errorf("moving announcements: %w", err)
+			}
+
+			if err := q.MoveGroupChapters(r.Context(), sqlc.MoveGroupChaptersParams(ids)); err != nil {
+				return fmt.Errorf("moving chapters: %w", err)
+			}
+
+			// A target that was a chapter of the source takes the source's place in the hierarchy.
+			if target.ParentID.Valid && target.ParentID.Int64 == sourceID {
+				if _, err := q.SetGroupParent(r.Context(), sqlc.SetGroupParentParams{
+					ID:       targetID,
+					ParentID: source.ParentID,
+				}); err != nil {
+					return fmt.Errorf("setting target parent: %w", err)
+				}
+			}
+
+			if _, err := q.SoftDeleteGroup(r.Context(), sourceID); err != nil {
+				return fmt.Errorf("deleting source group: %w", err)
+			}
+
+			if err := q.CreateGroupRedirect(r.Context(), sqlc.CreateGroupRedirectParams{
+				GroupID:    targetID,
+				OldGroupID: sourceID,
+			}); err != nil {
+				return fmt.Errorf("creating group redirect: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == internal.CheckViolationCode {
+				return middleware.Error(fmt.Errorf("%w: the target group is nested below a chapter of the source group", internal.ErrConflict))
+			}
+			return middleware.Error(fmt.Errorf("merging groups: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data: map[string]any{
+				"group_id":        targetID,
+				"merged_group_id": sourceID,
+				"members_moved":   members,
+				"members_deduped": duplicates,
+				"events_moved":    events,
+			},
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package groups
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+const (
+	maxTags      = 10
+	maxTagLength = 32
+)
+
+func ListCategories(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		categories, err := store.ListCategories(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing categories: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    categories,
+		})
+	}
+}
+
+func SetGroupCategory(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Category string `json:"category" zog:"category"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"Category": zog.String().Trim().Optional(),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating group category: %w", err))
+		}
+
+		if _, err := FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		var categoryID pgtype.Int8
+		if body.Category != "" {
+			category, err := store.GetCategoryBySlug(r.Context(), body.Category)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return middleware.Error(fmt.Errorf("category %w", internal.ErrNotExist))
+			}
+			if err != nil {
+				return middleware.Error(fmt.Errorf("getting category %s: %w", body.Category, err))
+			}
+			categoryID = pgtype.Int8{Int64: category.ID, Valid: true}
+		}
+
+		group, err := store.SetGroupCategory(r.Context(), sqlc.SetGroupCategoryParams{
+			ID:         groupID,
+			CategoryID: categoryID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("group %w", internal.ErrNotExist))
+		}
+		if err != nil {
+			return middleware.Error(fmt.Errorf("setting group category: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    group,
+		})
+	}
+}
+
+// SetGroupTags replaces the tags of a group with the normalized, deduplicated set from the request.
+func SetGroupTags(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Tags []string `json:"tags" zog:"tags"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"Tags": zog.Slice(zog.String()).Max(maxTags, zog.Message(fmt.Sprintf("A group can have at most %d tags", maxTags))),
+		})
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating group tags: %w", err))
+		}
+
+		if _, err := FindActive(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		if err := RequireAdmin(r.Context(), store, groupID); err != nil {
+			return middleware.Error(err)
+		}
+
+		names := normalizeTags(body.Tags)
+		var tags []sqlc.Tag
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			if err := q.DeleteGroupTags(r.Context(), groupID); err != nil {
+				return fmt.Errorf("deleting group tags: %w", err)
+			}
+
+			if len(names) == 0 {
+				return nil
+			}
+
+			created, err := q.UpsertTags(r.Context(), names)
+			if err != nil {
+				return fmt.Errorf("upserting tags: %w", err)
+			}
+
+			tagIDs := make([]int64, 0, len(created))
+			for _, tag := range created {
+				tagIDs = append(tagIDs, tag.ID)
+			}
+
+			err = q.AddGroupTags(r.Context(), sqlc.AddGroupTagsParams{
+				GroupID: groupID,
+				TagIds:  tagIDs,
+			})
+			if err != nil {
+				return fmt.Errorf("adding group tags: %w", err)
+			}
+
+			tags, err = q.ListGroupTags(r.Context(), groupID)
+			if err != nil {
+				return fmt.Errorf("listing group tags: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("setting group tags: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    tags,
+		})
+	}
+}
+
+// PopularTags returns the most used tags across all groups along with the number of groups using them.
+func PopularTags(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		limit, _ := pagination(r)
+
+		tags, err := store.ListPopularTags(r.Context(), int32(limit))
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing popular tags: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    tags,
+		})
+	}
+}
+
+// normalizeTags normalizes every tag and drops empty values and duplicates while preserving order.
+func normalizeTags(raw []string) []string {
+	seen := make(map[string]bool, len(raw))
+	tags := make([]string, 0, len(raw))
+
+	for _, tag := range raw {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// normalizeTag lowercases a tag and collapses every run of non alphanumeric characters into a
+// single dash, so "Board Games", "board_games" and " board-games " are all stored as "board-games".
+func normalizeTag(tag string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(tag)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if utf8.RuneCountInString(b.String()) >= maxTagLength {
+				return strings.TrimSuffix(b.String(), "-")
+			}
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
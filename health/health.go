@@ -0,0 +1,119 @@
+// Package health provides the liveness and readiness probes Cloud Run and load balancers check instances
+// with.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ship-labs/meet-loop-api/internal/pkg/postgres"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// checkTimeout bounds the database checks of a readiness probe, so probes fail instead of hanging when
+// the database does not answer.
+const checkTimeout = 2 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusDraining    = "draining"
+	statusUnavailable = "unavailable"
+	statusPending     = "pending"
+	statusDirty       = "dirty"
+	statusUnknown     = "unknown"
+)
+
+// Probe reports whether the instance is ready to serve traffic.
+type Probe struct {
+	store *sqlc.Store
+	// migration is the version of the newest migration the API was built with.
+	migration uint64
+	draining  atomic.Bool
+}
+
+func NewProbe(store *sqlc.Store) (*Probe, error) {
+	migration, err := postgres.LatestMigration()
+	if err != nil {
+		return nil, fmt.Errorf("getting latest migration: %w", err)
+	}
+
+	return &Probe{store: store, migration: migration}, nil
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing requests to the instance while
+// it finishes those in flight.
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+// Live reports that the process is up and serving requests. It checks nothing else, so that an outage of
+// the database does not get healthy instances restarted.
+func Live() middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		w.Header().Set("Cache-Control", "no-store")
+		return middleware.JSON(middleware.Response{Message: http.StatusText(http.StatusOK)})
+	}
+}
+
+// Ready reports whether the instance can serve traffic: it is not shutting down, the database answers
+// within a timeout and every migration the API was built with has been applied to it. It responds with
+// 503 Service Unavailable and the result of each check otherwise.
+func (p *Probe) Ready() middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		w.Header().Set("Cache-Control", "no-store")
+
+		checks := p.check(r.Context())
+		for _, status := range checks {
+			if status != statusOK {
+				return middleware.Code(http.StatusServiceUnavailable, middleware.JSON(middleware.Response{
+					Error:   "not ready",
+					Message: http.StatusText(http.StatusServiceUnavailable),
+					Data:    checks,
+				}))
+			}
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    checks,
+		})
+	}
+}
+
+func (p *Probe) check(ctx context.Context) map[string]string {
+	// A draining instance is going away whatever the state of the database, which is spared the checks.
+	if p.draining.Load() {
+		return map[string]string{"shutdown": statusDraining, "database": statusUnknown, "migrations": statusUnknown}
+	}
+
+	checks := map[string]string{"shutdown": statusOK, "database": statusOK, "migrations": statusOK}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if err := p.store.Ping(ctx); err != nil {
+		slog.WarnContext(ctx, "health", "message", "pinging database", "error", err)
+		checks["database"], checks["migrations"] = statusUnavailable, statusUnknown
+		return checks
+	}
+
+	version, dirty, err := p.store.MigrationVersion(ctx)
+	switch {
+	case err != nil:
+		slog.WarnContext(ctx, "health", "message", "reading migration version", "error", err)
+		checks["migrations"] = statusUnknown
+	case dirty:
+		checks["migrations"] = statusDirty
+	// Only older schemas fail, as instances of the previous release keep running against the migrated
+	// one during a rollout.
+	case version < p.migration:
+		checks["migrations"] = statusPending
+	}
+
+	return checks
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognize and scan for.
+const APIKeyPrefix = "mlk"
+
+// GenerateAPIKey returns a new API key of the form mlk_<prefix>_<secret>, along with its prefix, which
+// identifies the key, and the hash of its secret, which is all that is stored.
+func GenerateAPIKey() (key, prefix string, hash []byte, err error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", nil, fmt.Errorf("generating API key prefix: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", nil, fmt.Errorf("generating API key secret: %w", err)
+	}
+
+	prefix = hex.EncodeToString(id)
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+
+	return APIKeyPrefix + "_" + prefix + "_" + encoded, prefix, HashAPIKeySecret(encoded), nil
+}
+
+// ParseAPIKey splits an API key into its prefix and secret.
+func ParseAPIKey(key string) (prefix, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix+"_")
+	if !ok {
+		return "", "", false
+	}
+
+	prefix, secret, ok = strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", "", false
+	}
+
+	return prefix, secret, true
+}
+
+// HashAPIKeySecret hashes an API key secret for storage. The secret is random and long enough that a
+// plain SHA-256 needs no salt or stretching.
+func HashAPIKeySecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// APIKeySecretMatches reports, in constant time, whether secret hashes to hash.
+func APIKeySecretMatches(secret string, hash []byte) bool {
+	return subtle.ConstantTimeCompare(HashAPIKeySecret(secret), hash) == 1
+}
@@ -9,8 +9,27 @@ type Achievement struct {
 
 const (
 	UniqueViolationCode = "23505"
+	CheckViolationCode  = "23514"
 	InvestorsLimit      = 150
 	ProgramRoot         = "cmd"
+
+	// ObjectNotInPrerequisiteStateCode is raised by the database when writing to an archived group.
+	ObjectNotInPrerequisiteStateCode = "55000"
+
+	// InsufficientPrivilegeCode is raised by the database when a row-level security policy or trigger rejects a write.
+	InsufficientPrivilegeCode = "42501"
+
+	// ForeignKeyViolationCode is raised by the database when a row references one that does not exist.
+	ForeignKeyViolationCode = "23503"
+
+	// UndefinedTableCode is raised by the database when a query reads a table that does not exist.
+	UndefinedTableCode = "42P01"
+
+	// SerializationFailureCode is raised by the database when a transaction conflicts with a concurrent one.
+	SerializationFailureCode = "40001"
+
+	// DeadlockDetectedCode is raised by the database when it aborts a transaction to break a deadlock.
+	DeadlockDetectedCode = "40P01"
 )
 
 var Achievements = []Achievement{
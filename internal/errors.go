@@ -7,6 +7,8 @@ import (
 
 var (
 	ErrExists         = errors.New("already exists")
+	ErrConflict       = errors.New("conflict")
+	ErrArchived       = errors.New("group is archived")
 	ErrNotExist       = errors.New("does not exist")
 	ErrInvalidRequest = errors.New("invalid request")
 	ErrGatewayError   = errors.New("error")
@@ -16,4 +18,5 @@ var (
 	ErrUnmarshall   = errors.New("unmarshalling json error")
 	ErrUnauthorized = errors.New(http.StatusText(http.StatusUnauthorized))
 	ErrForbidden    = errors.New(http.StatusText(http.StatusForbidden))
+	ErrRateLimited  = errors.New("too many requests")
 )
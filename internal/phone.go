@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// NormalizePhone parses a phone number written in international format, or in the local format of
+// region, and returns it in E.164 form such as +14155552671.
+func NormalizePhone(raw, region string) (string, error) {
+	number, err := phonenumbers.Parse(raw, region)
+	if err != nil {
+		return "", fmt.Errorf("%w: parsing phone number %q: %w", ErrInvalidRequest, raw, err)
+	}
+
+	if !phonenumbers.IsValidNumber(number) {
+		return "", fmt.Errorf("%w: invalid phone number %q", ErrInvalidRequest, raw)
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
@@ -0,0 +1,15 @@
+ALTER TABLE "groups" DROP CONSTRAINT IF EXISTS "groups_category_id_fkey";
+
+ALTER TABLE "group_tags" DROP CONSTRAINT IF EXISTS "group_tags_group_id_fkey";
+
+ALTER TABLE "group_tags" DROP CONSTRAINT IF EXISTS "group_tags_tag_id_fkey";
+
+DROP INDEX IF EXISTS "groups_category_id_idx";
+
+ALTER TABLE "groups" DROP COLUMN IF EXISTS "category_id";
+
+DROP TABLE IF EXISTS "group_tags";
+
+DROP TABLE IF EXISTS "tags";
+
+DROP TABLE IF EXISTS "categories";
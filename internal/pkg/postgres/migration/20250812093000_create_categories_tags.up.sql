@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS "categories" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "slug" TEXT NOT NULL,
+  "name" TEXT NOT NULL,
+  "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS "tags" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "name" TEXT NOT NULL,
+  "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS "group_tags" (
+  "group_id" BIGINT NOT NULL,
+  "tag_id" BIGINT NOT NULL,
+  "created_at" TIMESTAMP DEFAULT (now()),
+  PRIMARY KEY ("group_id", "tag_id")
+);
+
+ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "category_id" BIGINT;
+
+CREATE UNIQUE INDEX ON "categories" ("slug");
+
+CREATE UNIQUE INDEX ON "tags" ("name");
+
+CREATE INDEX ON "group_tags" ("tag_id");
+
+CREATE INDEX ON "groups" ("category_id");
+
+ALTER TABLE "groups" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE "group_tags" ADD FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "group_tags" ADD FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- Curated taxonomy, slugs are part of the public API so never rename them
+INSERT INTO "categories" ("slug", "name") VALUES
+  ('arts-culture', 'Arts & Culture'),
+  ('business', 'Business & Networking'),
+  ('career', 'Career & Professional Development'),
+  ('community', 'Community & Volunteering'),
+  ('education', 'Education & Learning'),
+  ('faith', 'Faith & Spirituality'),
+  ('family', 'Parents & Family'),
+  ('food-drink', 'Food & Drink'),
+  ('gaming', 'Games & Gaming'),
+  ('health-wellness', 'Health & Wellness'),
+  ('language', 'Language & Culture Exchange'),
+  ('music', 'Music'),
+  ('outdoors', 'Outdoors & Adventure'),
+  ('social', 'Social'),
+  ('sports-fitness', 'Sports & Fitness'),
+  ('technology', 'Technology')
+ON CONFLICT DO NOTHING;
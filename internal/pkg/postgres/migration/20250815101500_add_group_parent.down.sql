@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS "groups_prevent_hierarchy_cycle" ON "groups";
+
+DROP FUNCTION IF EXISTS prevent_group_hierarchy_cycle();
+
+ALTER TABLE "groups" DROP CONSTRAINT IF EXISTS "groups_parent_id_fkey";
+
+ALTER TABLE "groups" DROP CONSTRAINT IF EXISTS "groups_parent_id_not_self";
+
+DROP INDEX IF EXISTS "groups_parent_id_idx";
+
+ALTER TABLE "groups" DROP COLUMN IF EXISTS "parent_id";
@@ -0,0 +1,40 @@
+ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "parent_id" BIGINT;
+
+ALTER TABLE "groups" ADD CONSTRAINT "groups_parent_id_not_self" CHECK ("parent_id" <> "id");
+
+CREATE INDEX ON "groups" ("parent_id");
+
+ALTER TABLE "groups" ADD FOREIGN KEY ("parent_id") REFERENCES "groups" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- A group may not become a chapter of one of its own chapters
+CREATE OR REPLACE FUNCTION prevent_group_hierarchy_cycle() RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.parent_id IS NULL THEN
+    RETURN NEW;
+  END IF;
+
+  -- Re-parents are serialized, as two concurrent ones such as A under B and B under A would each pass
+  -- the check below without seeing the other and close a cycle together. The lock is held until the
+  -- transaction ends, and the check runs on a snapshot taken after it is granted.
+  PERFORM pg_advisory_xact_lock(hashtext('groups_hierarchy'));
+
+  IF EXISTS (
+    WITH RECURSIVE ancestors AS (
+      SELECT id, parent_id FROM groups WHERE id = NEW.parent_id
+      UNION
+      SELECT g.id, g.parent_id FROM groups g
+      JOIN ancestors a ON g.id = a.parent_id
+    )
+    SELECT 1 FROM ancestors WHERE id = NEW.id
+  ) THEN
+    RAISE EXCEPTION 'group % cannot be a chapter of its descendant %', NEW.id, NEW.parent_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "groups_prevent_hierarchy_cycle"
+BEFORE INSERT OR UPDATE OF "parent_id" ON "groups"
+FOR EACH ROW EXECUTE FUNCTION prevent_group_hierarchy_cycle();
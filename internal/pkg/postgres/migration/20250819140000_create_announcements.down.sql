@@ -0,0 +1,13 @@
+ALTER TABLE "announcement_deliveries" DROP CONSTRAINT IF EXISTS "announcement_deliveries_member_id_fkey";
+
+ALTER TABLE "announcement_deliveries" DROP CONSTRAINT IF EXISTS "announcement_deliveries_announcement_id_fkey";
+
+ALTER TABLE "announcements" DROP CONSTRAINT IF EXISTS "announcements_author_member_id_fkey";
+
+ALTER TABLE "announcements" DROP CONSTRAINT IF EXISTS "announcements_event_id_fkey";
+
+ALTER TABLE "announcements" DROP CONSTRAINT IF EXISTS "announcements_group_id_fkey";
+
+DROP TABLE IF EXISTS "announcement_deliveries";
+
+DROP TABLE IF EXISTS "announcements";
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS "announcements" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "group_id" BIGINT NOT NULL,
+  "event_id" BIGINT, -- when set, only members who RSVP'd to the event receive the announcement
+  "author_member_id" BIGINT,
+  "title" TEXT NOT NULL,
+  "body" TEXT NOT NULL,
+  "created_at" TIMESTAMP DEFAULT (now()),
+  "updated_at" TIMESTAMP,
+  "deleted_at" TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "announcement_deliveries" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "announcement_id" BIGINT NOT NULL,
+  "member_id" BIGINT NOT NULL,
+  "channel" TEXT NOT NULL,
+  "status" TEXT NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'sent', 'failed', 'skipped')),
+  "error" TEXT,
+  "sent_at" TIMESTAMP,
+  "read_at" TIMESTAMP,
+  "created_at" TIMESTAMP DEFAULT (now()),
+  "updated_at" TIMESTAMP
+);
+
+CREATE INDEX ON "announcements" ("group_id", "id");
+
+CREATE UNIQUE INDEX ON "announcement_deliveries" ("announcement_id", "member_id", "channel");
+
+CREATE INDEX ON "announcement_deliveries" ("member_id", "channel");
+
+ALTER TABLE "announcements" ADD FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "announcements" ADD FOREIGN KEY ("event_id") REFERENCES "events" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE "announcements" ADD FOREIGN KEY ("author_member_id") REFERENCES "members" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE "announcement_deliveries" ADD FOREIGN KEY ("announcement_id") REFERENCES "announcements" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "announcement_deliveries" ADD FOREIGN KEY ("member_id") REFERENCES "members" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
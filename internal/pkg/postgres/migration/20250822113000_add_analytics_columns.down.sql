@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "members_group_id_created_at_idx";
+
+DROP INDEX IF EXISTS "events_group_id_starts_at_idx";
+
+ALTER TABLE "rsvps" DROP CONSTRAINT IF EXISTS "rsvps_attendance_check";
+
+ALTER TABLE "rsvps" DROP COLUMN IF EXISTS "attendance";
+
+ALTER TABLE "events" DROP COLUMN IF EXISTS "starts_at";
+
+ALTER TABLE "groups" DROP COLUMN IF EXISTS "time_zone";
@@ -0,0 +1,11 @@
+ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "time_zone" TEXT NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "starts_at" TIMESTAMP;
+
+ALTER TABLE "rsvps" ADD COLUMN IF NOT EXISTS "attendance" TEXT;
+
+ALTER TABLE "rsvps" ADD CONSTRAINT "rsvps_attendance_check" CHECK ("attendance" IN ('attended', 'no_show'));
+
+CREATE INDEX ON "events" ("group_id", "starts_at");
+
+CREATE INDEX ON "members" ("group_id", "created_at");
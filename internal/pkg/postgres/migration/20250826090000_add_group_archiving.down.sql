@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS "rsvps_ensure_group_not_archived" ON "rsvps";
+
+DROP TRIGGER IF EXISTS "events_ensure_group_not_archived" ON "events";
+
+DROP TRIGGER IF EXISTS "members_ensure_group_not_archived" ON "members";
+
+DROP FUNCTION IF EXISTS ensure_group_not_archived();
+
+DROP INDEX IF EXISTS "groups_deleted_at_idx";
+
+ALTER TABLE "groups" DROP COLUMN IF EXISTS "archived_at";
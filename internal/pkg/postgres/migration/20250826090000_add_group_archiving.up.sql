@@ -0,0 +1,35 @@
+ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "archived_at" TIMESTAMP;
+
+CREATE INDEX ON "groups" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+-- Archived groups are read-only: no new members, events or RSVPs
+CREATE OR REPLACE FUNCTION ensure_group_not_archived() RETURNS TRIGGER AS $$
+DECLARE
+  target_group_id BIGINT;
+BEGIN
+  IF TG_TABLE_NAME = 'rsvps' THEN
+    SELECT group_id INTO target_group_id FROM events WHERE id = NEW.event_id;
+  ELSE
+    target_group_id := NEW.group_id;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM groups WHERE id = target_group_id AND archived_at IS NOT NULL) THEN
+    RAISE EXCEPTION 'group % is archived', target_group_id
+      USING ERRCODE = 'object_not_in_prerequisite_state';
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "members_ensure_group_not_archived"
+BEFORE INSERT ON "members"
+FOR EACH ROW EXECUTE FUNCTION ensure_group_not_archived();
+
+CREATE TRIGGER "events_ensure_group_not_archived"
+BEFORE INSERT ON "events"
+FOR EACH ROW EXECUTE FUNCTION ensure_group_not_archived();
+
+CREATE TRIGGER "rsvps_ensure_group_not_archived"
+BEFORE INSERT ON "rsvps"
+FOR EACH ROW EXECUTE FUNCTION ensure_group_not_archived();
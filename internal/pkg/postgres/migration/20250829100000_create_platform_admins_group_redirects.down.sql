@@ -0,0 +1,9 @@
+ALTER TABLE "group_redirects" DROP CONSTRAINT IF EXISTS "group_redirects_group_id_fkey";
+
+ALTER TABLE "platform_admins" DROP CONSTRAINT IF EXISTS "platform_admins_user_id_fkey";
+
+DROP INDEX IF EXISTS "group_redirects_group_id_idx";
+
+DROP TABLE IF EXISTS "group_redirects";
+
+DROP TABLE IF EXISTS "platform_admins";
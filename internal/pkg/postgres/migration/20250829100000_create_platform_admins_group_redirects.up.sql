@@ -0,0 +1,17 @@
+-- Platform admins operate across every group, e.g. to merge duplicates. Rows are granted by hand.
+CREATE TABLE IF NOT EXISTS "platform_admins" (
+  "user_id" UUID PRIMARY KEY,
+  "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE TABLE IF NOT EXISTS "group_redirects" (
+  "old_group_id" BIGINT PRIMARY KEY, -- no foreign key, the old group is purged once its grace period runs out
+  "group_id" BIGINT NOT NULL,
+  "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX ON "group_redirects" ("group_id");
+
+ALTER TABLE "platform_admins" ADD FOREIGN KEY ("user_id") REFERENCES "auth"."users" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "group_redirects" ADD FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
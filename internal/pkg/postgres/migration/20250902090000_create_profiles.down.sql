@@ -0,0 +1,3 @@
+ALTER TABLE "profiles" DROP CONSTRAINT IF EXISTS "profiles_user_id_fkey";
+
+DROP TABLE IF EXISTS "profiles";
@@ -0,0 +1,13 @@
+-- One profile per auth user, created lazily from the token claims on their first request
+CREATE TABLE IF NOT EXISTS "profiles" (
+  "user_id" UUID PRIMARY KEY,
+  "display_name" TEXT NOT NULL,
+  "bio" TEXT,
+  "avatar_url" TEXT,
+  "city" TEXT,
+  "interests" TEXT[] NOT NULL DEFAULT '{}',
+  "created_at" TIMESTAMP DEFAULT (now()),
+  "updated_at" TIMESTAMP
+);
+
+ALTER TABLE "profiles" ADD FOREIGN KEY ("user_id") REFERENCES "auth"."users" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
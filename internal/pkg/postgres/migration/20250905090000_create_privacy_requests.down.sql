@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "privacy_requests_user_id_idx";
+
+DROP TABLE IF EXISTS "privacy_requests";
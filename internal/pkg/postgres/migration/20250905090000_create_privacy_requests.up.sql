@@ -0,0 +1,10 @@
+-- Audit trail of data subject requests. There is no foreign key so the trail outlives the auth user.
+CREATE TABLE IF NOT EXISTS "privacy_requests" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" UUID NOT NULL,
+  "kind" TEXT NOT NULL CHECK ("kind" IN ('export', 'erasure')),
+  "details" JSONB,
+  "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX ON "privacy_requests" ("user_id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "members_phone_e164_idx";
+
+ALTER TABLE "members" DROP COLUMN IF EXISTS "phone_e164";
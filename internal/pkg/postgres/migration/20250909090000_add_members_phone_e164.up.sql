@@ -0,0 +1,5 @@
+ALTER TABLE "members" ADD COLUMN IF NOT EXISTS "phone_e164" TEXT;
+
+-- Existing numbers are normalized with the configured default region by the backfill the API runs on startup,
+-- which validates them the same way new numbers are.
+CREATE INDEX ON "members" ("phone_e164") WHERE "phone_e164" IS NOT NULL;
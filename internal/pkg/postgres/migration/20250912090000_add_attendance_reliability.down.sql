@@ -0,0 +1,11 @@
+DROP VIEW IF EXISTS "member_attendance";
+
+DROP INDEX IF EXISTS "rsvps_member_id_event_id_idx";
+
+ALTER TABLE "groups" DROP CONSTRAINT IF EXISTS "groups_min_reliability_check";
+
+ALTER TABLE "groups" DROP COLUMN IF EXISTS "min_reliability";
+
+ALTER TABLE "rsvps" DROP CONSTRAINT IF EXISTS "rsvps_attendance_check";
+
+ALTER TABLE "rsvps" ADD CONSTRAINT "rsvps_attendance_check" CHECK ("attendance" IN ('attended', 'no_show'));
@@ -0,0 +1,24 @@
+ALTER TABLE "rsvps" DROP CONSTRAINT IF EXISTS "rsvps_attendance_check";
+
+ALTER TABLE "rsvps" ADD CONSTRAINT "rsvps_attendance_check" CHECK ("attendance" IN ('attended', 'no_show', 'late_cancellation'));
+
+-- Members whose reliability score is below the threshold cannot RSVP to the group's events
+ALTER TABLE "groups" ADD COLUMN IF NOT EXISTS "min_reliability" DOUBLE PRECISION;
+
+ALTER TABLE "groups" ADD CONSTRAINT "groups_min_reliability_check" CHECK ("min_reliability" BETWEEN 0 AND 1);
+
+CREATE UNIQUE INDEX ON "rsvps" ("member_id", "event_id") WHERE "deleted_at" IS NULL;
+
+-- Attendance history per membership. Late cancellations are soft deleted RSVPs, so they are counted too,
+-- unlike the RSVPs cancelled early enough not to count against the member.
+CREATE OR REPLACE VIEW "member_attendance" AS
+SELECT m.id AS member_id,
+       m.user_id,
+       m.group_id,
+       COUNT(r.id) FILTER (WHERE r.deleted_at IS NULL OR r.attendance = 'late_cancellation') AS rsvps,
+       COUNT(r.id) FILTER (WHERE r.attendance = 'attended') AS attended,
+       COUNT(r.id) FILTER (WHERE r.attendance = 'no_show') AS no_shows,
+       COUNT(r.id) FILTER (WHERE r.attendance = 'late_cancellation') AS late_cancellations
+FROM members m
+LEFT JOIN rsvps r ON r.member_id = m.id
+GROUP BY m.id, m.user_id, m.group_id;
@@ -0,0 +1,21 @@
+ALTER TABLE "notification_preferences" DROP CONSTRAINT IF EXISTS "notification_preferences_group_id_fkey";
+
+ALTER TABLE "notification_preferences" DROP CONSTRAINT IF EXISTS "notification_preferences_user_id_fkey";
+
+ALTER TABLE "notification_settings" DROP CONSTRAINT IF EXISTS "notification_settings_user_id_fkey";
+
+DROP INDEX IF EXISTS "announcement_deliveries_deliver_after_idx";
+
+UPDATE "announcement_deliveries" SET "status" = 'pending' WHERE "status" = 'sending';
+
+ALTER TABLE "announcement_deliveries" DROP CONSTRAINT IF EXISTS "announcement_deliveries_status_check";
+
+ALTER TABLE "announcement_deliveries" ADD CONSTRAINT "announcement_deliveries_status_check" CHECK ("status" IN ('pending', 'sent', 'failed', 'skipped'));
+
+ALTER TABLE "announcement_deliveries" DROP COLUMN IF EXISTS "deliver_after";
+
+DROP INDEX IF EXISTS "notification_preferences_user_id_group_id_category_channel_idx";
+
+DROP TABLE IF EXISTS "notification_preferences";
+
+DROP TABLE IF EXISTS "notification_settings";
@@ -0,0 +1,45 @@
+-- Quiet hours are wall-clock times in the user's time zone. A window whose end is before its start spans midnight.
+CREATE TABLE IF NOT EXISTS "notification_settings" (
+  "user_id" UUID PRIMARY KEY,
+  "time_zone" TEXT NOT NULL DEFAULT 'UTC',
+  "quiet_hours_start" TIME,
+  "quiet_hours_end" TIME,
+  "created_at" TIMESTAMP DEFAULT (now()),
+  "updated_at" TIMESTAMP,
+  CHECK (("quiet_hours_start" IS NULL) = ("quiet_hours_end" IS NULL))
+);
+
+-- Overrides of the default channels per category. Rows without a group apply to every group of the user,
+-- rows with a group take precedence for that group only.
+CREATE TABLE IF NOT EXISTS "notification_preferences" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" UUID NOT NULL,
+  "group_id" BIGINT,
+  "category" TEXT NOT NULL CHECK ("category" IN ('announcements', 'event_reminders', 'rsvp_updates', 'payment_receipts')),
+  "channel" TEXT NOT NULL CHECK ("channel" IN ('email', 'sms', 'in_app', 'push')),
+  "enabled" BOOLEAN NOT NULL,
+  "created_at" TIMESTAMP DEFAULT (now()),
+  "updated_at" TIMESTAMP
+);
+
+CREATE UNIQUE INDEX "notification_preferences_user_id_group_id_category_channel_idx"
+  ON "notification_preferences" ("user_id", COALESCE("group_id", 0), "category", "channel");
+
+-- Deliveries stay pending until deliver_after, which is their creation unless quiet hours hold them back. The
+-- instance that claims them once they are due marks them as sending, and deliver_after then holds when its
+-- claim expires.
+ALTER TABLE "announcement_deliveries" ADD COLUMN IF NOT EXISTS "deliver_after" TIMESTAMP;
+
+UPDATE "announcement_deliveries" SET "deliver_after" = COALESCE("created_at", now()) WHERE "status" = 'pending';
+
+ALTER TABLE "announcement_deliveries" DROP CONSTRAINT IF EXISTS "announcement_deliveries_status_check";
+
+ALTER TABLE "announcement_deliveries" ADD CONSTRAINT "announcement_deliveries_status_check" CHECK ("status" IN ('pending', 'sending', 'sent', 'failed', 'skipped'));
+
+CREATE INDEX ON "announcement_deliveries" ("deliver_after") WHERE "status" IN ('pending', 'sending') AND "deliver_after" IS NOT NULL;
+
+ALTER TABLE "notification_settings" ADD FOREIGN KEY ("user_id") REFERENCES "auth"."users" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "notification_preferences" ADD FOREIGN KEY ("user_id") REFERENCES "auth"."users" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "notification_preferences" ADD FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
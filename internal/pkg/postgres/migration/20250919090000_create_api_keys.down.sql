@@ -0,0 +1,7 @@
+ALTER TABLE "api_keys" DROP CONSTRAINT IF EXISTS "api_keys_created_by_fkey";
+
+ALTER TABLE "api_keys" DROP CONSTRAINT IF EXISTS "api_keys_group_id_fkey";
+
+DROP INDEX IF EXISTS "api_keys_group_id_idx";
+
+DROP TABLE IF EXISTS "api_keys";
@@ -0,0 +1,21 @@
+-- Group-scoped keys for partner systems. Only a SHA-256 hash of the secret is stored; the prefix
+-- identifies the key and is safe to show.
+CREATE TABLE IF NOT EXISTS "api_keys" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "group_id" BIGINT NOT NULL,
+  "name" TEXT NOT NULL,
+  "prefix" TEXT NOT NULL UNIQUE,
+  "secret_hash" BYTEA NOT NULL,
+  "scopes" TEXT[] NOT NULL CHECK ("scopes" <@ ARRAY['events:read', 'members:read', 'rsvps:read', 'rsvps:write']),
+  "created_by" UUID,
+  "expires_at" TIMESTAMP,
+  "last_used_at" TIMESTAMP,
+  "revoked_at" TIMESTAMP,
+  "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX ON "api_keys" ("group_id");
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("created_by") REFERENCES "auth"."users" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,71 @@
+DROP FUNCTION IF EXISTS "user_attendance"(UUID);
+
+ALTER VIEW "member_attendance" RESET (security_invoker);
+
+DROP POLICY IF EXISTS "api_keys_update" ON "api_keys";
+
+DROP POLICY IF EXISTS "api_keys_insert" ON "api_keys";
+
+DROP POLICY IF EXISTS "api_keys_select" ON "api_keys";
+
+DROP POLICY IF EXISTS "platform_admins_select" ON "platform_admins";
+
+DROP POLICY IF EXISTS "group_admins_delete" ON "group_admins";
+
+DROP POLICY IF EXISTS "group_admins_insert" ON "group_admins";
+
+DROP POLICY IF EXISTS "group_admins_select" ON "group_admins";
+
+DROP TRIGGER IF EXISTS "rsvps_protect_columns" ON "rsvps";
+
+DROP FUNCTION IF EXISTS "protect_rsvp_columns"();
+
+DROP POLICY IF EXISTS "rsvps_update" ON "rsvps";
+
+DROP POLICY IF EXISTS "rsvps_insert" ON "rsvps";
+
+DROP POLICY IF EXISTS "rsvps_select" ON "rsvps";
+
+DROP POLICY IF EXISTS "events_update" ON "events";
+
+DROP POLICY IF EXISTS "events_insert" ON "events";
+
+DROP POLICY IF EXISTS "events_select" ON "events";
+
+DROP POLICY IF EXISTS "members_delete" ON "members";
+
+DROP POLICY IF EXISTS "members_update" ON "members";
+
+DROP POLICY IF EXISTS "members_insert" ON "members";
+
+DROP POLICY IF EXISTS "members_select" ON "members";
+
+DROP TRIGGER IF EXISTS "groups_protect_columns" ON "groups";
+
+DROP FUNCTION IF EXISTS "protect_group_columns"();
+
+DROP POLICY IF EXISTS "groups_update" ON "groups";
+
+DROP POLICY IF EXISTS "groups_insert" ON "groups";
+
+DROP POLICY IF EXISTS "groups_select" ON "groups";
+
+ALTER TABLE "api_keys" DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "platform_admins" DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "group_admins" DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "rsvps" DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "events" DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "members" DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "groups" DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS "member_group_id"(BIGINT);
+
+DROP FUNCTION IF EXISTS "is_group_admin"(BIGINT);
+
+DROP FUNCTION IF EXISTS "is_group_member"(BIGINT);
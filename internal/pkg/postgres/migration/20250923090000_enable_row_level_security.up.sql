@@ -0,0 +1,269 @@
+-- Row-level security is defense in depth: the API already authorizes every request, and it bypasses these
+-- policies for background jobs and API keys, but queries run as the signed in user's role when
+-- DB_ROW_LEVEL_SECURITY is enabled, and Supabase clients querying the tables directly are held to them.
+
+-- The helpers are security definer so policies on members can look up members without recursing.
+CREATE OR REPLACE FUNCTION "is_group_member"("group_id" BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = '' AS $$
+    SELECT EXISTS(
+        SELECT 1 FROM "public"."members" m
+        WHERE m.group_id = $1 AND m.user_id = auth.uid() AND m.deleted_at IS NULL
+    );
+$$;
+
+-- Admins of a parent group administer all of its chapters, and platform admins administer every group.
+CREATE OR REPLACE FUNCTION "is_group_admin"("group_id" BIGINT) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = '' AS $$
+    WITH RECURSIVE lineage AS (
+        SELECT g.id, g.parent_id FROM "public"."groups" g WHERE g.id = $1
+        UNION
+        SELECT g.id, g.parent_id FROM "public"."groups" g
+        JOIN lineage l ON g.id = l.parent_id
+    )
+    SELECT EXISTS(
+        SELECT 1 FROM lineage l
+        JOIN "public"."group_admins" ga ON ga.group_id = l.id
+        JOIN "public"."members" m ON m.id = ga.member_id
+        WHERE m.user_id = auth.uid()
+    ) OR EXISTS(
+        SELECT 1 FROM "public"."platform_admins" pa
+        WHERE pa.user_id = auth.uid()
+    );
+$$;
+
+-- The stored group of a membership, so an update can be checked against it without the policy recursing.
+CREATE OR REPLACE FUNCTION "member_group_id"("member_id" BIGINT) RETURNS BIGINT
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = '' AS $$
+    SELECT m.group_id FROM "public"."members" m WHERE m.id = $1;
+$$;
+
+ALTER TABLE "groups" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "members" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "events" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "rsvps" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "group_admins" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "platform_admins" ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE "api_keys" ENABLE ROW LEVEL SECURITY;
+
+-- Groups and their events are listed to every signed in user.
+CREATE POLICY "groups_select" ON "groups" FOR SELECT TO authenticated
+USING (true);
+
+CREATE POLICY "groups_insert" ON "groups" FOR INSERT TO authenticated
+WITH CHECK ("user_id" = auth.uid());
+
+-- Owners may update their groups so erasing their account can clear the ownership, which is all they may
+-- change of a group they no longer administer.
+CREATE POLICY "groups_update" ON "groups" FOR UPDATE TO authenticated
+USING ("user_id" = auth.uid() OR is_group_admin("id"))
+WITH CHECK ("user_id" IS NULL OR is_group_admin("id"));
+
+-- Ownership is only ever given up by the owner, chapters are only moved below groups the admin administers
+-- too, and owners who are not admins only give up the ownership.
+CREATE OR REPLACE FUNCTION "protect_group_columns"() RETURNS TRIGGER
+LANGUAGE plpgsql SET search_path = '' AS $$
+BEGIN
+  IF auth.uid() IS NULL THEN
+    RETURN NEW;
+  END IF;
+
+  IF NEW.id <> OLD.id OR NEW.created_at IS DISTINCT FROM OLD.created_at
+    OR (NEW.user_id IS DISTINCT FROM OLD.user_id AND NOT (OLD.user_id = auth.uid() AND NEW.user_id IS NULL)) THEN
+    RAISE EXCEPTION 'only the owner can give up the ownership of a group'
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+
+  IF "public".is_group_admin(OLD.id) THEN
+    IF NEW.parent_id IS DISTINCT FROM OLD.parent_id AND NEW.parent_id IS NOT NULL
+      AND NOT "public".is_group_admin(NEW.parent_id) THEN
+      RAISE EXCEPTION 'only admins of the parent group can add chapters to it'
+        USING ERRCODE = 'insufficient_privilege';
+    END IF;
+    RETURN NEW;
+  END IF;
+
+  IF to_jsonb(NEW) - 'user_id' - 'updated_at' <> to_jsonb(OLD) - 'user_id' - 'updated_at' THEN
+    RAISE EXCEPTION 'only group admins can change a group'
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER "groups_protect_columns"
+BEFORE UPDATE ON "groups"
+FOR EACH ROW EXECUTE FUNCTION "protect_group_columns"();
+
+CREATE POLICY "members_select" ON "members" FOR SELECT TO authenticated
+USING ("user_id" = auth.uid() OR is_group_member("group_id") OR is_group_admin("group_id"));
+
+-- Users only join groups they own, as the creator of a group does, or administer.
+CREATE POLICY "members_insert" ON "members" FOR INSERT TO authenticated
+WITH CHECK (
+    "user_id" = auth.uid()
+    AND (
+        EXISTS (SELECT 1 FROM "groups" g WHERE g.id = "members"."group_id" AND g.user_id = auth.uid())
+        OR is_group_admin("group_id")
+    )
+);
+
+-- Members may update their own membership, but only admins may move it to another group.
+CREATE POLICY "members_update" ON "members" FOR UPDATE TO authenticated
+USING ("user_id" = auth.uid() OR is_group_admin("group_id"))
+WITH CHECK (is_group_admin("group_id") OR ("user_id" = auth.uid() AND "group_id" = member_group_id("id")));
+
+-- Merging duplicate groups deletes the duplicate memberships.
+CREATE POLICY "members_delete" ON "members" FOR DELETE TO authenticated
+USING (is_group_admin("group_id"));
+
+CREATE POLICY "events_select" ON "events" FOR SELECT TO authenticated
+USING (true);
+
+CREATE POLICY "events_insert" ON "events" FOR INSERT TO authenticated
+WITH CHECK (is_group_admin("group_id"));
+
+CREATE POLICY "events_update" ON "events" FOR UPDATE TO authenticated
+USING (is_group_admin("group_id"));
+
+CREATE POLICY "rsvps_select" ON "rsvps" FOR SELECT TO authenticated
+USING (
+    EXISTS (SELECT 1 FROM "members" m WHERE m.id = "member_id" AND m.user_id = auth.uid())
+    OR is_group_admin((SELECT e.group_id FROM "events" e WHERE e.id = "event_id"))
+);
+
+-- Members only RSVP to the events of their own group.
+CREATE POLICY "rsvps_insert" ON "rsvps" FOR INSERT TO authenticated
+WITH CHECK (
+    EXISTS (
+        SELECT 1 FROM "members" m
+        JOIN "events" e ON e.group_id = m.group_id
+        WHERE m.id = "member_id" AND e.id = "event_id" AND m.user_id = auth.uid()
+    )
+);
+
+CREATE POLICY "rsvps_update" ON "rsvps" FOR UPDATE TO authenticated
+USING (
+    EXISTS (SELECT 1 FROM "members" m WHERE m.id = "member_id" AND m.user_id = auth.uid())
+    OR is_group_admin((SELECT e.group_id FROM "events" e WHERE e.id = "event_id"))
+);
+
+-- Members may RSVP to and cancel their own RSVPs, but payments and attendance are recorded by the group's
+-- admins and the API's own role, and an RSVP is only cancelled late within a day of its event.
+CREATE OR REPLACE FUNCTION "protect_rsvp_columns"() RETURNS TRIGGER
+LANGUAGE plpgsql SET search_path = '' AS $$
+DECLARE
+  event "public"."events"%ROWTYPE;
+BEGIN
+  SELECT * INTO event FROM "public"."events" e WHERE e.id = NEW.event_id;
+
+  IF auth.uid() IS NULL OR "public".is_group_admin(event.group_id) THEN
+    RETURN NEW;
+  END IF;
+
+  IF TG_OP = 'INSERT' THEN
+    IF NEW.has_paid OR NEW.payment_data IS NOT NULL OR NEW.payment_reference_id IS NOT NULL
+      OR NEW.attendance IS NOT NULL THEN
+      RAISE EXCEPTION 'only group admins can record payments and attendance'
+        USING ERRCODE = 'insufficient_privilege';
+    END IF;
+    RETURN NEW;
+  END IF;
+
+  IF NEW.member_id IS DISTINCT FROM OLD.member_id OR NEW.event_id IS DISTINCT FROM OLD.event_id
+    OR NEW.has_paid IS DISTINCT FROM OLD.has_paid OR NEW.payment_data IS DISTINCT FROM OLD.payment_data
+    OR NEW.payment_reference_id IS DISTINCT FROM OLD.payment_reference_id
+    OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+    RAISE EXCEPTION 'only group admins can change the member, event or payment of an rsvp'
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+
+  IF NEW.attendance IS NOT DISTINCT FROM OLD.attendance AND NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at THEN
+    RETURN NEW;
+  END IF;
+
+  -- Members change attendance only by cancelling, which is late within a day of the event. The API decides
+  -- after the transaction started, so a cancellation is late by either the start or the current time.
+  IF OLD.deleted_at IS NOT NULL OR NEW.deleted_at IS NULL
+    OR (NEW.attendance IS NULL AND event.starts_at < now() + INTERVAL '24 hours')
+    OR (NEW.attendance IS NOT NULL AND NOT (
+      NEW.attendance = 'late_cancellation' AND event.starts_at < clock_timestamp() + INTERVAL '24 hours'
+    )) THEN
+    RAISE EXCEPTION 'only group admins can record attendance'
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER "rsvps_protect_columns"
+BEFORE INSERT OR UPDATE ON "rsvps"
+FOR EACH ROW EXECUTE FUNCTION "protect_rsvp_columns"();
+
+-- Members see who administers their groups, and users see their own admin rows.
+CREATE POLICY "group_admins_select" ON "group_admins" FOR SELECT TO authenticated
+USING (
+    EXISTS (SELECT 1 FROM "members" m WHERE m.id = "member_id" AND m.user_id = auth.uid())
+    OR is_group_member("group_id")
+    OR is_group_admin("group_id")
+);
+
+-- The creator of a group becomes its first admin, and admins appoint the others.
+CREATE POLICY "group_admins_insert" ON "group_admins" FOR INSERT TO authenticated
+WITH CHECK (
+    EXISTS (SELECT 1 FROM "groups" g WHERE g.id = "group_admins"."group_id" AND g.user_id = auth.uid())
+    OR is_group_admin("group_id")
+);
+
+-- Erasing an account removes the user's own admin rows, and merging groups removes the duplicate's.
+CREATE POLICY "group_admins_delete" ON "group_admins" FOR DELETE TO authenticated
+USING (
+    EXISTS (SELECT 1 FROM "members" m WHERE m.id = "member_id" AND m.user_id = auth.uid())
+    OR is_group_admin("group_id")
+);
+
+-- Platform admins are only appointed by the API's own role.
+CREATE POLICY "platform_admins_select" ON "platform_admins" FOR SELECT TO authenticated
+USING ("user_id" = auth.uid());
+
+CREATE POLICY "api_keys_select" ON "api_keys" FOR SELECT TO authenticated
+USING (is_group_admin("group_id"));
+
+CREATE POLICY "api_keys_insert" ON "api_keys" FOR INSERT TO authenticated
+WITH CHECK (is_group_admin("group_id") AND "created_by" = auth.uid());
+
+CREATE POLICY "api_keys_update" ON "api_keys" FOR UPDATE TO authenticated
+USING (is_group_admin("group_id"))
+WITH CHECK (is_group_admin("group_id"));
+
+-- Attendance histories are held to the policies of the user querying them.
+ALTER VIEW "member_attendance" SET (security_invoker = true);
+
+-- The reliability of a user across the platform counts memberships the caller cannot see, so it is summed
+-- with the rights of the function's owner. Only the totals are returned, and only to the user themselves,
+-- the admins of their groups and the API's own role.
+CREATE OR REPLACE FUNCTION "user_attendance"("user_id" UUID)
+RETURNS TABLE ("rsvps" BIGINT, "attended" BIGINT, "no_shows" BIGINT, "late_cancellations" BIGINT)
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = '' AS $$
+    SELECT COALESCE(SUM(a.rsvps), 0)::bigint,
+           COALESCE(SUM(a.attended), 0)::bigint,
+           COALESCE(SUM(a.no_shows), 0)::bigint,
+           COALESCE(SUM(a.late_cancellations), 0)::bigint
+    FROM "public"."member_attendance" a
+    WHERE a.user_id = $1
+      AND (
+          auth.uid() IS NULL
+          OR $1 = auth.uid()
+          OR EXISTS (
+              SELECT 1 FROM "public"."members" m
+              WHERE m.user_id = $1 AND "public".is_group_admin(m.group_id)
+          )
+      );
+$$;
@@ -0,0 +1,14 @@
+DROP POLICY IF EXISTS "members_insert" ON "members";
+
+CREATE POLICY "members_insert" ON "members" FOR INSERT TO authenticated
+WITH CHECK (
+    "user_id" = auth.uid()
+    AND (
+        EXISTS (SELECT 1 FROM "groups" g WHERE g.id = "members"."group_id" AND g.user_id = auth.uid())
+        OR is_group_admin("group_id")
+    )
+);
+
+ALTER TABLE "events" DROP CONSTRAINT IF EXISTS "events_visibility_check";
+
+ALTER TABLE "events" DROP COLUMN IF EXISTS "visibility";
@@ -0,0 +1,25 @@
+-- Public events are open to every signed in user, anonymous sessions included, while members events are
+-- left out of what anonymous sessions can browse and RSVP to.
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "visibility" TEXT NOT NULL DEFAULT 'public';
+
+ALTER TABLE "events" ADD CONSTRAINT "events_visibility_check" CHECK ("visibility" IN ('public', 'members'));
+
+-- Anonymous sessions join a group as guests on their first RSVP to one of its free public events.
+DROP POLICY IF EXISTS "members_insert" ON "members";
+
+CREATE POLICY "members_insert" ON "members" FOR INSERT TO authenticated
+WITH CHECK (
+    "user_id" = auth.uid()
+    AND (
+        EXISTS (SELECT 1 FROM "groups" g WHERE g.id = "members"."group_id" AND g.user_id = auth.uid())
+        OR (
+            COALESCE((auth.jwt()->>'is_anonymous')::boolean, false)
+            AND EXISTS (
+                SELECT 1 FROM "events" e
+                WHERE e.group_id = "members"."group_id" AND e.visibility = 'public' AND NOT COALESCE(e.is_paid, false)
+                  AND e.deleted_at IS NULL
+            )
+        )
+        OR is_group_admin("group_id")
+    )
+);
@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS "session_revocations_notify" ON "session_revocations";
+
+DROP FUNCTION IF EXISTS notify_session_revocation();
+
+ALTER TABLE "session_revocations" DROP CONSTRAINT IF EXISTS "session_revocations_revoked_by_fkey";
+
+ALTER TABLE "session_revocations" DROP CONSTRAINT IF EXISTS "session_revocations_user_id_fkey";
+
+DROP POLICY IF EXISTS "session_revocations_insert" ON "session_revocations";
+
+DROP POLICY IF EXISTS "session_revocations_select" ON "session_revocations";
+
+ALTER TABLE "session_revocations" DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS "session_revocations_revoked_at_idx";
+
+DROP TABLE IF EXISTS "session_revocations";
@@ -0,0 +1,38 @@
+-- Access tokens stay valid until they expire, so signing a user out everywhere or banning them only takes
+-- effect on the API once the tokens issued before the revocation are rejected.
+CREATE TABLE IF NOT EXISTS "session_revocations" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "user_id" UUID NOT NULL,
+  "session_id" UUID, -- every session of the user when null
+  "reason" TEXT,
+  "revoked_by" UUID,
+  "revoked_at" TIMESTAMP NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "session_revocations" ("revoked_at");
+
+-- Users may only revoke and see their own sessions, as erasing their account does; everything else is left to
+-- the API's own role, which bypasses row-level security.
+ALTER TABLE "session_revocations" ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY "session_revocations_select" ON "session_revocations" FOR SELECT TO authenticated
+USING ("user_id" = auth.uid());
+
+CREATE POLICY "session_revocations_insert" ON "session_revocations" FOR INSERT TO authenticated
+WITH CHECK ("user_id" = auth.uid() AND "revoked_by" = auth.uid());
+
+-- Every API instance keeps the revocations in memory and reloads them when notified.
+CREATE OR REPLACE FUNCTION notify_session_revocation() RETURNS TRIGGER AS $$
+BEGIN
+  PERFORM pg_notify('session_revocations', NEW.user_id::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "session_revocations_notify"
+AFTER INSERT ON "session_revocations"
+FOR EACH ROW EXECUTE FUNCTION notify_session_revocation();
+
+ALTER TABLE "session_revocations" ADD FOREIGN KEY ("user_id") REFERENCES "auth"."users" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "session_revocations" ADD FOREIGN KEY ("revoked_by") REFERENCES "auth"."users" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE "rate_limit_buckets" DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS "rate_limit_buckets_updated_at_idx";
+
+DROP TABLE IF EXISTS "rate_limit_buckets";
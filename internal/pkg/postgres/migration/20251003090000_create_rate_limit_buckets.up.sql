@@ -0,0 +1,12 @@
+-- Token buckets of the rate limiter shared by every API instance. Losing them on a crash only resets the
+-- limits, so the table skips the write-ahead log.
+CREATE UNLOGGED TABLE IF NOT EXISTS "rate_limit_buckets" (
+  "key" TEXT PRIMARY KEY,
+  "tokens" DOUBLE PRECISION NOT NULL,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "rate_limit_buckets" ("updated_at");
+
+-- Without policies only the API's own role, which bypasses row-level security, can use the buckets.
+ALTER TABLE "rate_limit_buckets" ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,35 @@
+// Package postgres embeds the database migrations, so the API can tell whether the schema it runs against
+// is up to date.
+package postgres
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed migration/*.up.sql
+var migrations embed.FS
+
+// LatestMigration returns the version of the newest migration, which golang-migrate records in
+// schema_migrations once it has been applied.
+func LatestMigration() (uint64, error) {
+	files, err := fs.Glob(migrations, "migration/*.up.sql")
+	if err != nil {
+		return 0, fmt.Errorf("listing migrations: %w", err)
+	}
+
+	var latest uint64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(strings.TrimPrefix(file, "migration/"), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing version of migration %s: %w", file, err)
+		}
+		latest = max(latest, version)
+	}
+
+	return latest, nil
+}
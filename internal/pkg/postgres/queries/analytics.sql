@@ -0,0 +1,73 @@
+-- name: GetMemberGrowth :many
+-- Counts members joining per bucket along with the running member total.
+WITH joins AS (
+    SELECT date_trunc(@bucket::text, (m.created_at AT TIME ZONE 'UTC') AT TIME ZONE @time_zone::text) AS bucket,
+           COUNT(*) AS joined
+    FROM members m
+    WHERE m.group_id = @group_id AND m.deleted_at IS NULL AND m.created_at >= @since::timestamp
+    GROUP BY 1
+)
+SELECT j.bucket::timestamp AS bucket,
+       j.joined,
+       (SUM(j.joined) OVER (ORDER BY j.bucket) + (
+           SELECT COUNT(*) FROM members
+           WHERE group_id = @group_id AND deleted_at IS NULL AND created_at < @since::timestamp
+       ))::bigint AS total
+FROM joins j
+ORDER BY j.bucket;
+
+-- name: GetEventStats :many
+-- Aggregates events held, RSVP conversion, no-shows and revenue per bucket.
+WITH event_stats AS (
+    SELECT e.id,
+           date_trunc(@bucket::text, (e.starts_at AT TIME ZONE 'UTC') AT TIME ZONE @time_zone::text) AS bucket,
+           COUNT(r.id) AS rsvps,
+           COUNT(r.id) FILTER (WHERE r.attendance = 'attended') AS attended,
+           COUNT(r.id) FILTER (WHERE r.attendance = 'no_show') AS no_shows,
+           COALESCE(SUM(e.amount) FILTER (WHERE r.has_paid), 0) AS revenue
+    FROM events e
+    LEFT JOIN rsvps r ON r.event_id = e.id AND r.deleted_at IS NULL
+    WHERE e.group_id = @group_id
+      AND e.deleted_at IS NULL
+      AND e.starts_at >= @since::timestamp
+      AND e.starts_at < now()
+    GROUP BY e.id
+),
+buckets AS (
+    SELECT bucket,
+           COUNT(*) AS events_held,
+           SUM(rsvps)::bigint AS rsvps,
+           SUM(attended)::bigint AS attended,
+           SUM(no_shows)::bigint AS no_shows,
+           SUM(revenue)::bigint AS revenue
+    FROM event_stats
+    GROUP BY bucket
+)
+SELECT b.bucket::timestamp AS bucket,
+       b.events_held,
+       b.rsvps,
+       b.attended,
+       b.no_shows,
+       COALESCE(b.attended::float8 / NULLIF(b.rsvps, 0), 0)::float8 AS attendance_rate,
+       COALESCE(b.no_shows::float8 / NULLIF(b.attended + b.no_shows, 0), 0)::float8 AS no_show_rate,
+       b.revenue,
+       SUM(b.revenue) OVER (ORDER BY b.bucket)::bigint AS cumulative_revenue
+FROM buckets b
+ORDER BY b.bucket;
+
+-- name: GetMostEngagedMembers :many
+SELECT m.id AS member_id,
+       COALESCE(p.display_name, m.name)::text AS name,
+       COUNT(r.id) AS rsvps,
+       COUNT(r.id) FILTER (WHERE r.attendance = 'attended') AS attended,
+       RANK() OVER (ORDER BY COUNT(r.id) FILTER (WHERE r.attendance = 'attended') DESC, COUNT(r.id) DESC) AS rank
+FROM members m
+JOIN rsvps r ON r.member_id = m.id AND r.deleted_at IS NULL
+JOIN events e ON e.id = r.event_id AND e.deleted_at IS NULL
+LEFT JOIN profiles p ON p.user_id = m.user_id
+WHERE m.group_id = @group_id
+  AND m.deleted_at IS NULL
+  AND e.starts_at >= @since::timestamp
+GROUP BY m.id, m.name, p.display_name
+ORDER BY rank, m.id
+LIMIT @max_results;
@@ -0,0 +1,84 @@
+-- name: CreateAnnouncement :one
+INSERT INTO announcements (group_id, event_id, author_member_id, title, body)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAnnouncement :one
+SELECT * FROM announcements
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListAnnouncementRecipients :many
+SELECT m.* FROM members m
+WHERE m.group_id = sqlc.arg('group_id') AND m.deleted_at IS NULL
+  AND (sqlc.narg('event_id')::bigint IS NULL OR EXISTS (
+      SELECT 1 FROM rsvps r
+      WHERE r.member_id = m.id AND r.event_id = sqlc.narg('event_id') AND r.deleted_at IS NULL
+  ))
+ORDER BY m.id;
+
+-- name: CreateAnnouncementDeliveries :many
+INSERT INTO announcement_deliveries (announcement_id, member_id, channel, deliver_after)
+SELECT @announcement_id::bigint, unnest(@member_ids::bigint[]), unnest(@channels::text[]), unnest(@deliver_afters::timestamp[])
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: UpdateAnnouncementDelivery :exec
+UPDATE announcement_deliveries
+SET status = $2,
+    error = $3,
+    sent_at = CASE WHEN $2 = 'sent' THEN now() ELSE sent_at END,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: ListAnnouncementDeliveries :many
+SELECT * FROM announcement_deliveries
+WHERE announcement_id = $1
+ORDER BY id;
+
+-- name: ListMemberAnnouncements :many
+-- Lists the announcements delivered in-app to a user within a group, newest first.
+SELECT a.id, a.group_id, a.event_id, a.author_member_id, a.title, a.body, a.created_at, d.read_at
+FROM announcements a
+JOIN announcement_deliveries d ON d.announcement_id = a.id AND d.channel = 'in_app'
+JOIN members m ON m.id = d.member_id
+WHERE a.group_id = sqlc.arg('group_id')
+  AND m.user_id = sqlc.arg('user_id')
+  AND a.deleted_at IS NULL
+  AND (sqlc.narg('before')::bigint IS NULL OR a.id < sqlc.narg('before'))
+ORDER BY a.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: MarkAnnouncementRead :execrows
+UPDATE announcement_deliveries d
+SET read_at = now(), updated_at = now()
+FROM members m
+WHERE d.member_id = m.id
+  AND d.announcement_id = $1
+  AND m.user_id = $2
+  AND d.channel = 'in_app'
+  AND d.read_at IS NULL;
+
+-- name: ClaimDueAnnouncementDeliveries :many
+-- Claims the deliveries that are due, oldest first, so no other instance sends them too. Claimed
+-- deliveries are sending until lease_until, after which those whose outcome was never recorded are claimed
+-- again.
+WITH due AS (
+    SELECT id FROM announcement_deliveries
+    WHERE status IN ('pending', 'sending')
+      AND deliver_after <= now()
+    ORDER BY deliver_after, id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE announcement_deliveries d
+SET status = 'sending', deliver_after = sqlc.arg('lease_until'), updated_at = now()
+FROM due, announcements a, members m
+WHERE d.id = due.id
+  AND a.id = d.announcement_id
+  AND m.id = d.member_id
+RETURNING d.id, d.channel, a.group_id, m.id AS member_id, m.user_id, m.name, m.email, m.phone, m.phone_e164, a.title, a.body;
+
+-- name: RescheduleAnnouncementDelivery :exec
+UPDATE announcement_deliveries
+SET status = 'pending', deliver_after = $2, updated_at = now()
+WHERE id = $1;
@@ -0,0 +1,33 @@
+-- name: ClaimAnonymousRsvps :exec
+-- Points the RSVPs of an anonymous user at the memberships the user already has in the same groups, unless they RSVPed to the event themselves.
+UPDATE rsvps r SET member_id = um.id, updated_at = now()
+FROM members am
+JOIN members um ON um.group_id = am.group_id AND um.user_id = sqlc.arg('user_id')
+WHERE r.member_id = am.id AND am.user_id = sqlc.arg('anonymous_user_id')
+  AND NOT EXISTS (
+      SELECT 1 FROM rsvps x
+      WHERE x.member_id = um.id AND x.event_id = r.event_id AND x.deleted_at IS NULL
+  );
+
+-- name: ClaimAnonymousDeliveries :exec
+UPDATE announcement_deliveries d SET member_id = um.id, updated_at = now()
+FROM members am
+JOIN members um ON um.group_id = am.group_id AND um.user_id = sqlc.arg('user_id')
+WHERE d.member_id = am.id AND am.user_id = sqlc.arg('anonymous_user_id')
+  AND NOT EXISTS (
+      SELECT 1 FROM announcement_deliveries x
+      WHERE x.member_id = um.id AND x.announcement_id = d.announcement_id AND x.channel = d.channel
+  );
+
+-- name: DeleteClaimedAnonymousMembers :execrows
+-- Deletes the anonymous memberships in groups the user already belongs to. Run it after the claims.
+DELETE FROM members am
+USING members um
+WHERE am.user_id = sqlc.arg('anonymous_user_id')
+  AND um.user_id = sqlc.arg('user_id')
+  AND um.group_id = am.group_id;
+
+-- name: ReassignAnonymousMembers :execrows
+-- Hands the remaining memberships of an anonymous user, along with their RSVPs, to the user.
+UPDATE members SET user_id = sqlc.arg('user_id'), updated_at = now()
+WHERE user_id = sqlc.arg('anonymous_user_id');
@@ -0,0 +1,23 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (group_id, name, prefix, secret_hash, scopes, created_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, group_id, name, prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at;
+
+-- name: ListGroupApiKeys :many
+SELECT id, group_id, name, prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at FROM api_keys
+WHERE group_id = $1
+ORDER BY id DESC;
+
+-- name: GetApiKeyByPrefix :one
+SELECT * FROM api_keys
+WHERE prefix = $1 AND revoked_at IS NULL;
+
+-- name: RevokeApiKey :one
+UPDATE api_keys SET revoked_at = now()
+WHERE id = $1 AND group_id = $2 AND revoked_at IS NULL
+RETURNING id, group_id, name, prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at;
+
+-- name: TouchApiKey :exec
+-- Records that a key was used, at most once a minute so busy integrations do not write on every request.
+UPDATE api_keys SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
@@ -0,0 +1,11 @@
+-- name: ListCategories :many
+SELECT * FROM categories
+ORDER BY name;
+
+-- name: GetCategoryBySlug :one
+SELECT * FROM categories
+WHERE slug = $1;
+
+-- name: GetCategory :one
+SELECT * FROM categories
+WHERE id = $1;
@@ -0,0 +1,19 @@
+-- name: ListGroupTreeEvents :many
+-- Lists the events of a group and of all of its chapters.
+WITH RECURSIVE tree AS (
+    SELECT id FROM groups WHERE groups.id = sqlc.arg('id') AND deleted_at IS NULL
+    UNION
+    SELECT g.id FROM groups g
+    JOIN tree t ON g.parent_id = t.id
+    WHERE g.deleted_at IS NULL
+)
+SELECT e.* FROM events e
+JOIN tree t ON t.id = e.group_id
+WHERE e.deleted_at IS NULL
+  AND (sqlc.narg('visibility')::text IS NULL OR e.visibility = sqlc.narg('visibility'))
+ORDER BY e.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetEvent :one
+SELECT * FROM events
+WHERE id = $1 AND deleted_at IS NULL;
@@ -22,3 +22,103 @@ SELECT g.* FROM user_group_membership ugm
 JOIN group_admins ga ON ga.group_id = ugm.group_id AND ga.member_id = ugm.member_id
 JOIN groups g ON ga.group_id = g.id
 LIMIT $2;
+
+-- name: IsUserGroupAdmin :one
+-- Admins of a parent group inherit admin rights over all of its chapters.
+WITH RECURSIVE lineage AS (
+    SELECT id, parent_id FROM groups WHERE id = sqlc.arg('group_id')
+    UNION
+    SELECT g.id, g.parent_id FROM groups g
+    JOIN lineage l ON g.id = l.parent_id
+)
+SELECT EXISTS(
+    SELECT 1 FROM lineage l
+    JOIN group_admins ga ON ga.group_id = l.id
+    JOIN members m ON m.id = ga.member_id
+    WHERE m.user_id = sqlc.arg('user_id')
+) AS is_admin;
+
+-- name: GetGroup :one
+SELECT * FROM groups
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListGroups :many
+SELECT g.* FROM groups g
+LEFT JOIN categories c ON c.id = g.category_id
+WHERE g.deleted_at IS NULL
+  AND (sqlc.narg('category')::text IS NULL OR c.slug = sqlc.narg('category'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+      SELECT 1 FROM group_tags gt
+      JOIN tags t ON t.id = gt.tag_id
+      WHERE gt.group_id = g.id AND t.name = sqlc.narg('tag')
+  ))
+ORDER BY g.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SetGroupCategory :one
+UPDATE groups SET category_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetGroupParent :one
+UPDATE groups SET parent_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListChapters :many
+WITH RECURSIVE chapters AS (
+    SELECT g.*, 1 AS depth
+    FROM groups g
+    WHERE g.parent_id = $1 AND g.deleted_at IS NULL
+    UNION ALL
+    SELECT g.*, c.depth + 1
+    FROM groups g
+    JOIN chapters c ON g.parent_id = c.id
+    WHERE g.deleted_at IS NULL
+) CYCLE id SET is_cycle USING path
+SELECT id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id,
+    time_zone, archived_at, min_reliability, depth
+FROM chapters
+WHERE NOT is_cycle
+ORDER BY depth, name;
+
+-- name: SetGroupTimeZone :one
+UPDATE groups SET time_zone = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ArchiveGroup :one
+UPDATE groups SET archived_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL AND archived_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteGroup :one
+UPDATE groups SET deleted_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreGroup :one
+-- Unarchives a group and undoes its soft deletion, provided it was deleted after the grace period cutoff.
+UPDATE groups SET archived_at = NULL, deleted_at = NULL, updated_at = now()
+WHERE id = $1 AND (deleted_at IS NULL OR deleted_at > sqlc.arg('deleted_after')::timestamp)
+RETURNING *;
+
+-- name: PurgeDeletedGroups :execrows
+-- Hard deletes groups soft deleted before the cutoff, cascading to their members, events and RSVPs.
+DELETE FROM groups
+WHERE deleted_at < sqlc.arg('deleted_before')::timestamp;
+
+-- name: IsPlatformAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM platform_admins
+    WHERE user_id = $1
+) AS is_platform_admin;
+
+-- name: GetGroupRedirect :one
+SELECT group_id FROM group_redirects
+WHERE old_group_id = $1;
+
+-- name: SetGroupMinReliability :one
+UPDATE groups SET min_reliability = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
@@ -1,4 +1,83 @@
 -- name: CreateGroupMember :one
-INSERT INTO members (group_id, email, phone, name, user_id)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO members (group_id, email, phone, phone_e164, name, user_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
+
+-- name: ListGroupTreeMembers :many
+-- Lists the members of a group and of all of its chapters, as presented by their profiles.
+WITH RECURSIVE tree AS (
+    SELECT id FROM groups WHERE groups.id = $1 AND deleted_at IS NULL
+    UNION
+    SELECT g.id FROM groups g
+    JOIN tree t ON g.parent_id = t.id
+    WHERE g.deleted_at IS NULL
+)
+SELECT m.id, m.group_id, m.user_id, m.email, m.phone, m.phone_e164,
+    COALESCE(p.display_name, m.name)::text AS name,
+    p.avatar_url, p.bio, p.city, p.interests,
+    m.created_at
+FROM members m
+JOIN tree t ON t.id = m.group_id
+LEFT JOIN profiles p ON p.user_id = m.user_id
+WHERE m.deleted_at IS NULL
+ORDER BY m.id
+LIMIT $2 OFFSET $3;
+
+-- name: GetGroupMember :one
+SELECT * FROM members
+WHERE user_id = $1 AND group_id = $2 AND deleted_at IS NULL;
+
+-- name: ListUserMemberships :many
+-- Lists every group the user belongs to, newest membership first, with their role and what awaits them there.
+SELECT g.id, g.name, g.description, g.category_id, g.parent_id, g.archived_at,
+    m.id AS member_id,
+    m.created_at AS joined_at,
+    (CASE WHEN admin.is_admin THEN 'admin' ELSE 'member' END)::text AS role,
+    (
+        SELECT COUNT(*) FROM events e
+        WHERE e.group_id = g.id AND e.deleted_at IS NULL AND e.starts_at > now()
+    ) AS upcoming_events,
+    (
+        SELECT COUNT(*) FROM announcement_deliveries d
+        JOIN announcements a ON a.id = d.announcement_id AND a.deleted_at IS NULL
+        WHERE d.member_id = m.id AND d.channel = 'in_app' AND d.read_at IS NULL
+    ) AS unread_announcements
+FROM members m
+JOIN groups g ON g.id = m.group_id AND g.deleted_at IS NULL
+-- Admins of a parent group administer its chapters too, as IsUserGroupAdmin checks.
+CROSS JOIN LATERAL (
+    WITH RECURSIVE lineage AS (
+        SELECT g.id, g.parent_id
+        UNION
+        SELECT p.id, p.parent_id FROM groups p
+        JOIN lineage l ON p.id = l.parent_id
+    )
+    SELECT EXISTS(
+        SELECT 1 FROM lineage l
+        JOIN group_admins ga ON ga.group_id = l.id
+        JOIN members am ON am.id = ga.member_id
+        WHERE am.user_id = m.user_id
+    ) AS is_admin
+) admin
+WHERE m.user_id = sqlc.arg('user_id')
+  AND m.deleted_at IS NULL
+  AND (sqlc.narg('role')::text IS NULL OR (sqlc.narg('role') = 'admin') = admin.is_admin)
+  AND (sqlc.narg('search')::text IS NULL OR g.name ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('before')::bigint IS NULL OR m.id < sqlc.narg('before'))
+ORDER BY m.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListMembersWithUnnormalizedPhones :many
+SELECT * FROM members
+WHERE id > sqlc.arg('after') AND phone <> '' AND phone_e164 IS NULL
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: SetMemberPhoneE164 :exec
+UPDATE members SET phone_e164 = $2
+WHERE id = $1;
+
+-- name: UpdateUserPhone :execrows
+-- Sets the contact phone of every membership of the user.
+UPDATE members SET phone = sqlc.arg('phone'), phone_e164 = sqlc.narg('phone_e164'), updated_at = now()
+WHERE user_id = sqlc.arg('user_id') AND deleted_at IS NULL;
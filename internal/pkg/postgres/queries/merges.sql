@@ -0,0 +1,66 @@
+-- name: RemapDuplicateMemberRsvps :exec
+-- Points the RSVPs of source members who are also target members at their target membership.
+UPDATE rsvps r SET member_id = tm.id, updated_at = now()
+FROM members sm
+JOIN members tm ON tm.user_id = sm.user_id AND tm.group_id = sqlc.arg('target_id')
+WHERE r.member_id = sm.id AND sm.group_id = sqlc.arg('source_id');
+
+-- name: RemapDuplicateMemberDeliveries :exec
+UPDATE announcement_deliveries d SET member_id = tm.id, updated_at = now()
+FROM members sm
+JOIN members tm ON tm.user_id = sm.user_id AND tm.group_id = sqlc.arg('target_id')
+WHERE d.member_id = sm.id AND sm.group_id = sqlc.arg('source_id');
+
+-- name: RemapDuplicateMemberAuthors :exec
+UPDATE announcements a SET author_member_id = tm.id, updated_at = now()
+FROM members sm
+JOIN members tm ON tm.user_id = sm.user_id AND tm.group_id = sqlc.arg('target_id')
+WHERE a.author_member_id = sm.id AND sm.group_id = sqlc.arg('source_id');
+
+-- name: MergeGroupAdmins :exec
+-- Makes every source admin an admin of the target, through their target membership when they already have one.
+INSERT INTO group_admins (group_id, member_id)
+SELECT sqlc.arg('target_id')::bigint, COALESCE(tm.id, sm.id)
+FROM group_admins ga
+JOIN members sm ON sm.id = ga.member_id
+LEFT JOIN members tm ON tm.user_id = sm.user_id AND tm.group_id = sqlc.arg('target_id')
+WHERE ga.group_id = sqlc.arg('source_id')
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteGroupAdmins :exec
+DELETE FROM group_admins
+WHERE group_id = $1;
+
+-- name: DeleteDuplicateMembers :execrows
+-- Deletes the source memberships of users who are already target members. Run it after the remaps.
+DELETE FROM members sm
+USING members tm
+WHERE sm.group_id = sqlc.arg('source_id')
+  AND tm.group_id = sqlc.arg('target_id')
+  AND tm.user_id = sm.user_id;
+
+-- name: MoveGroupMembers :execrows
+UPDATE members SET group_id = sqlc.arg('target_id'), updated_at = now()
+WHERE group_id = sqlc.arg('source_id');
+
+-- name: MoveGroupEvents :execrows
+UPDATE events SET group_id = sqlc.arg('target_id'), updated_at = now()
+WHERE group_id = sqlc.arg('source_id');
+
+-- name: MoveGroupAnnouncements :exec
+UPDATE announcements SET group_id = sqlc.arg('target_id'), updated_at = now()
+WHERE group_id = sqlc.arg('source_id');
+
+-- name: MoveGroupChapters :exec
+UPDATE groups SET parent_id = sqlc.arg('target_id'), updated_at = now()
+WHERE parent_id = sqlc.arg('source_id') AND id <> sqlc.arg('target_id');
+
+-- name: CreateGroupRedirect :exec
+-- Redirects the old group to its replacement, along with any group that was already redirected to it.
+WITH repointed AS (
+    UPDATE group_redirects SET group_id = sqlc.arg('group_id')
+    WHERE group_redirects.group_id = sqlc.arg('old_group_id')
+)
+INSERT INTO group_redirects (old_group_id, group_id)
+VALUES (sqlc.arg('old_group_id'), sqlc.arg('group_id'))
+ON CONFLICT (old_group_id) DO UPDATE SET group_id = EXCLUDED.group_id, created_at = now();
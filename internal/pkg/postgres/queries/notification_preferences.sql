@@ -0,0 +1,39 @@
+-- name: GetNotificationSettings :one
+SELECT * FROM notification_settings
+WHERE user_id = $1;
+
+-- name: UpsertNotificationSettings :one
+INSERT INTO notification_settings (user_id, time_zone, quiet_hours_start, quiet_hours_end)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE SET
+    time_zone = EXCLUDED.time_zone,
+    quiet_hours_start = EXCLUDED.quiet_hours_start,
+    quiet_hours_end = EXCLUDED.quiet_hours_end,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListNotificationPreferences :many
+-- Lists the overrides of the users that apply to a group, user-wide ones first. Without a group only the
+-- user-wide overrides are returned.
+SELECT * FROM notification_preferences
+WHERE user_id = ANY(@user_ids::uuid[])
+  AND (group_id IS NULL OR group_id = sqlc.narg('group_id'))
+ORDER BY user_id, group_id NULLS FIRST, id;
+
+-- name: ListNotificationSettings :many
+SELECT * FROM notification_settings
+WHERE user_id = ANY(@user_ids::uuid[]);
+
+-- name: UpsertNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, group_id, category, channel, enabled)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, COALESCE(group_id, 0), category, channel) DO UPDATE SET
+    enabled = EXCLUDED.enabled,
+    updated_at = now();
+
+-- name: DeleteNotificationPreference :exec
+DELETE FROM notification_preferences
+WHERE user_id = $1
+  AND group_id IS NOT DISTINCT FROM $2
+  AND category = $3
+  AND channel = $4;
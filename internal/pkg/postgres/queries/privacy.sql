@@ -0,0 +1,85 @@
+-- name: ListUserMembers :many
+SELECT * FROM members
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: ListUserRsvps :many
+SELECT r.* FROM rsvps r
+JOIN members m ON m.id = r.member_id
+WHERE m.user_id = $1
+ORDER BY r.id;
+
+-- name: ListUserAnnouncements :many
+-- Lists the announcements the user authored.
+SELECT a.* FROM announcements a
+JOIN members m ON m.id = a.author_member_id
+WHERE m.user_id = $1
+ORDER BY a.id;
+
+-- name: ListUserAnnouncementDeliveries :many
+-- Lists the announcements the user received on every channel.
+SELECT a.id AS announcement_id, a.group_id, a.title, a.body, d.channel, d.status, d.sent_at, d.read_at
+FROM announcement_deliveries d
+JOIN announcements a ON a.id = d.announcement_id
+JOIN members m ON m.id = d.member_id
+WHERE m.user_id = $1
+ORDER BY d.id;
+
+-- name: CreatePrivacyRequest :one
+INSERT INTO privacy_requests (user_id, kind, details)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: AnonymizeProfile :exec
+-- Keeps an anonymized profile rather than deleting it, so it is not recreated from the token claims.
+INSERT INTO profiles (user_id, display_name)
+VALUES ($1, 'Deleted user')
+ON CONFLICT (user_id) DO UPDATE SET
+    display_name = EXCLUDED.display_name,
+    bio = NULL,
+    avatar_url = NULL,
+    city = NULL,
+    interests = '{}',
+    updated_at = now();
+
+-- name: AnonymizeUserMembers :execrows
+-- Scrubs the contact details of every membership of the user. The rows are kept, soft deleted, so the
+-- RSVPs and payments that reference them stay intact for accounting.
+UPDATE members SET
+    name = 'Deleted member',
+    email = NULL,
+    phone = '',
+    phone_e164 = NULL,
+    updated_at = now(),
+    deleted_at = COALESCE(deleted_at, now())
+WHERE user_id = $1;
+
+-- name: ListSoleAdminGroups :many
+-- Lists the groups the user is the only admin of, which erasing their account would leave without one.
+SELECT g.id, g.name FROM groups g
+JOIN group_admins ga ON ga.group_id = g.id
+JOIN members m ON m.id = ga.member_id
+WHERE m.user_id = $1 AND g.deleted_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM group_admins oa
+      JOIN members om ON om.id = oa.member_id
+      WHERE oa.group_id = g.id AND om.user_id <> $1
+  )
+ORDER BY g.id;
+
+-- name: DeleteUserGroupAdmins :execrows
+DELETE FROM group_admins ga
+USING members m
+WHERE m.id = ga.member_id AND m.user_id = $1;
+
+-- name: ClearUserGroupOwnership :execrows
+UPDATE groups SET user_id = NULL, updated_at = now()
+WHERE user_id = $1;
+
+-- name: DeleteUserNotificationPreferences :execrows
+DELETE FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: DeleteUserNotificationSettings :exec
+DELETE FROM notification_settings
+WHERE user_id = $1;
@@ -0,0 +1,23 @@
+-- name: GetProfile :one
+SELECT * FROM profiles
+WHERE user_id = $1;
+
+-- name: CreateProfile :one
+-- Creates the profile or returns the existing one when a concurrent request got there first.
+INSERT INTO profiles (user_id, display_name, avatar_url)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING *;
+
+-- name: UpdateProfile :one
+-- Only updates the fields that are set. An empty bio, avatar or city clears it, while the display name
+-- cannot be cleared.
+UPDATE profiles SET
+    display_name = COALESCE(NULLIF(sqlc.narg('display_name'), ''), display_name),
+    bio = NULLIF(COALESCE(sqlc.narg('bio'), bio), ''),
+    avatar_url = NULLIF(COALESCE(sqlc.narg('avatar_url'), avatar_url), ''),
+    city = NULLIF(COALESCE(sqlc.narg('city'), city), ''),
+    interests = COALESCE(sqlc.narg('interests')::text[], interests),
+    updated_at = now()
+WHERE user_id = sqlc.arg('user_id')
+RETURNING *;
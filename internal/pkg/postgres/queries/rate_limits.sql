@@ -0,0 +1,17 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket of key at rate tokens a second up to capacity and takes a token from it. A negative result means the bucket was empty: the request is denied and the bucket holds one token more than the result.
+INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+VALUES (sqlc.arg(key), sqlc.arg(capacity)::float8 - 1, now())
+ON CONFLICT (key) DO UPDATE SET
+  tokens = LEAST(
+      sqlc.arg(capacity)::float8,
+      CASE WHEN b.tokens < 0 THEN b.tokens + 1 ELSE b.tokens END
+          + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * sqlc.arg(rate)::float8
+  ) - 1,
+  updated_at = now()
+RETURNING tokens;
+
+-- name: PruneRateLimitBuckets :execrows
+-- Deletes buckets untouched since before, which have refilled and are no different from missing ones.
+DELETE FROM rate_limit_buckets
+WHERE updated_at < sqlc.arg(before);
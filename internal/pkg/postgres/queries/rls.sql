@@ -0,0 +1,3 @@
+-- name: SetRequestClaims :exec
+-- Assumes the role of the signed in user and exposes their claims to row-level security policies until the transaction ends.
+SELECT set_config('role', sqlc.arg('role')::text, true), set_config('request.jwt.claims', sqlc.arg('claims')::text, true);
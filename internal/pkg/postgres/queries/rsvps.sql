@@ -0,0 +1,44 @@
+-- name: CreateRsvp :one
+INSERT INTO rsvps (member_id, event_id)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetMemberRsvp :one
+SELECT * FROM rsvps
+WHERE member_id = $1 AND event_id = $2 AND deleted_at IS NULL;
+
+-- name: CancelRsvp :one
+-- Withdraws an RSVP, recording it as a late cancellation when attendance is set.
+UPDATE rsvps SET attendance = sqlc.narg('attendance'), deleted_at = now(), updated_at = now()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetRsvpAttendance :one
+-- Records the outcome of an active RSVP. Cancelled RSVPs keep the outcome of their cancellation.
+UPDATE rsvps r SET attendance = sqlc.narg('attendance'), updated_at = now()
+FROM events e
+WHERE r.id = sqlc.arg('id') AND e.id = r.event_id AND e.group_id = sqlc.arg('group_id') AND r.deleted_at IS NULL
+RETURNING r.*;
+
+-- name: ListEventAttendees :many
+-- Lists the RSVPs to an event with each member's attendance history in the group and across the platform.
+SELECT r.id AS rsvp_id, r.attendance, r.has_paid, r.created_at,
+    m.id AS member_id,
+    COALESCE(p.display_name, m.name)::text AS name,
+    ma.rsvps, ma.attended, ma.no_shows, ma.late_cancellations,
+    pa.rsvps AS platform_rsvps,
+    pa.attended AS platform_attended,
+    pa.no_shows AS platform_no_shows,
+    pa.late_cancellations AS platform_late_cancellations
+FROM rsvps r
+JOIN members m ON m.id = r.member_id
+JOIN member_attendance ma ON ma.member_id = m.id
+CROSS JOIN LATERAL user_attendance(m.user_id) pa
+LEFT JOIN profiles p ON p.user_id = m.user_id
+WHERE r.event_id = $1 AND r.deleted_at IS NULL
+ORDER BY r.id;
+
+-- name: GetUserAttendance :one
+-- Sums the attendance history of every membership of the user, including those the caller cannot see.
+SELECT rsvps, attended, no_shows, late_cancellations
+FROM user_attendance($1);
@@ -0,0 +1,14 @@
+-- name: CreateSessionRevocation :one
+INSERT INTO session_revocations (user_id, session_id, reason, revoked_by)
+VALUES (sqlc.arg(user_id), sqlc.narg(session_id), sqlc.narg(reason), sqlc.arg(revoked_by))
+RETURNING *;
+
+-- name: ListSessionRevocations :many
+SELECT * FROM session_revocations
+WHERE revoked_at > sqlc.arg(since)
+ORDER BY revoked_at;
+
+-- name: PruneSessionRevocations :execrows
+-- Deletes revocations older than any token they could still reject.
+DELETE FROM session_revocations
+WHERE revoked_at < sqlc.arg(before);
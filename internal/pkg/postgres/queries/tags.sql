@@ -0,0 +1,28 @@
+-- name: UpsertTags :many
+INSERT INTO tags (name)
+SELECT unnest(@names::text[])
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: AddGroupTags :exec
+INSERT INTO group_tags (group_id, tag_id)
+SELECT @group_id::bigint, unnest(@tag_ids::bigint[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteGroupTags :exec
+DELETE FROM group_tags
+WHERE group_id = $1;
+
+-- name: ListGroupTags :many
+SELECT t.* FROM tags t
+JOIN group_tags gt ON gt.tag_id = t.id
+WHERE gt.group_id = $1
+ORDER BY t.name;
+
+-- name: ListPopularTags :many
+SELECT t.name, COUNT(gt.group_id) AS group_count FROM tags t
+JOIN group_tags gt ON gt.tag_id = t.id
+JOIN groups g ON g.id = gt.group_id AND g.deleted_at IS NULL
+GROUP BY t.name
+ORDER BY group_count DESC, t.name
+LIMIT $1;
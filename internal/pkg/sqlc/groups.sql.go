@@ -11,10 +11,36 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveGroup = `-- name: ArchiveGroup :one
+UPDATE groups SET archived_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL AND archived_at IS NULL
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+func (q *Queries) ArchiveGroup(ctx context.Context, id int64) (Group, error) {
+	row := q.db.QueryRow(ctx, archiveGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
 const createGroup = `-- name: CreateGroup :one
 INSERT INTO groups (name, description, user_id)
 VALUES ($1, $2, $3)
-RETURNING id, name, user_id, description, created_at, updated_at, deleted_at
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
 `
 
 type CreateGroupParams struct {
@@ -34,6 +60,11 @@ func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Group
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
 	)
 	return i, err
 }
@@ -63,11 +94,48 @@ func (q *Queries) CreateGroupAdmin(ctx context.Context, arg CreateGroupAdminPara
 	return i, err
 }
 
+const getGroup = `-- name: GetGroup :one
+SELECT id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability FROM groups
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetGroup(ctx context.Context, id int64) (Group, error) {
+	row := q.db.QueryRow(ctx, getGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
+const getGroupRedirect = `-- name: GetGroupRedirect :one
+SELECT group_id FROM group_redirects
+WHERE old_group_id = $1
+`
+
+func (q *Queries) GetGroupRedirect(ctx context.Context, oldGroupID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getGroupRedirect, oldGroupID)
+	var group_id int64
+	err := row.Scan(&group_id)
+	return group_id, err
+}
+
 const getUserGrops = `-- name: GetUserGrops :many
 WITH user_group_membership AS (
     SELECT id AS member_id, group_id FROM members WHERE members.user_id = $1
 )
-SELECT g.id, g.name, g.user_id, g.description, g.created_at, g.updated_at, g.deleted_at FROM user_group_membership ugm
+SELECT g.id, g.name, g.user_id, g.description, g.created_at, g.updated_at, g.deleted_at, g.category_id, g.parent_id, g.time_zone, g.archived_at, g.min_reliability FROM user_group_membership ugm
 JOIN group_admins ga ON ga.group_id = ugm.group_id AND ga.member_id = ugm.member_id
 JOIN groups g ON ga.group_id = g.id
 LIMIT $2
@@ -95,6 +163,11 @@ func (q *Queries) GetUserGrops(ctx context.Context, arg GetUserGropsParams) ([]G
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.CategoryID,
+			&i.ParentID,
+			&i.TimeZone,
+			&i.ArchivedAt,
+			&i.MinReliability,
 		); err != nil {
 			return nil, err
 		}
@@ -124,3 +197,366 @@ func (q *Queries) IsGroupAdmin(ctx context.Context, arg IsGroupAdminParams) (boo
 	err := row.Scan(&is_admin)
 	return is_admin, err
 }
+
+const isPlatformAdmin = `-- name: IsPlatformAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM platform_admins
+    WHERE user_id = $1
+) AS is_platform_admin
+`
+
+func (q *Queries) IsPlatformAdmin(ctx context.Context, userID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isPlatformAdmin, userID)
+	var is_platform_admin bool
+	err := row.Scan(&is_platform_admin)
+	return is_platform_admin, err
+}
+
+const isUserGroupAdmin = `-- name: IsUserGroupAdmin :one
+WITH RECURSIVE lineage AS (
+    SELECT id, parent_id FROM groups WHERE id = $1
+    UNION
+    SELECT g.id, g.parent_id FROM groups g
+    JOIN lineage l ON g.id = l.parent_id
+)
+SELECT EXISTS(
+    SELECT 1 FROM lineage l
+    JOIN group_admins ga ON ga.group_id = l.id
+    JOIN members m ON m.id = ga.member_id
+    WHERE m.user_id = $2
+) AS is_admin
+`
+
+type IsUserGroupAdminParams struct {
+	GroupID int64       `json:"group_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+// Admins of a parent group inherit admin rights over all of its chapters.
+func (q *Queries) IsUserGroupAdmin(ctx context.Context, arg IsUserGroupAdminParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserGroupAdmin, arg.GroupID, arg.UserID)
+	var is_admin bool
+	err := row.Scan(&is_admin)
+	return is_admin, err
+}
+
+const listChapters = `-- name: ListChapters :many
+WITH RECURSIVE chapters AS (
+    SELECT g.id, g.name, g.user_id, g.description, g.created_at, g.updated_at, g.deleted_at, g.category_id, g.parent_id, g.time_zone, g.archived_at, g.min_reliability, 1 AS depth
+    FROM groups g
+    WHERE g.parent_id = $1 AND g.deleted_at IS NULL
+    UNION ALL
+    SELECT g.id, g.name, g.user_id, g.description, g.created_at, g.updated_at, g.deleted_at, g.category_id, g.parent_id, g.time_zone, g.archived_at, g.min_reliability, c.depth + 1
+    FROM groups g
+    JOIN chapters c ON g.parent_id = c.id
+    WHERE g.deleted_at IS NULL
+) CYCLE id SET is_cycle USING path
+SELECT id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability, depth FROM chapters
+WHERE NOT is_cycle
+ORDER BY depth, name
+`
+
+type ListChaptersRow struct {
+	ID             int64            `json:"id"`
+	Name           string           `json:"name"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	Description    pgtype.Text      `json:"description"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	CategoryID     pgtype.Int8      `json:"category_id"`
+	ParentID       pgtype.Int8      `json:"parent_id"`
+	TimeZone       string           `json:"time_zone"`
+	ArchivedAt     pgtype.Timestamp `json:"archived_at"`
+	MinReliability pgtype.Float8    `json:"min_reliability"`
+	Depth          int32            `json:"depth"`
+}
+
+func (q *Queries) ListChapters(ctx context.Context, parentID pgtype.Int8) ([]ListChaptersRow, error) {
+	rows, err := q.db.Query(ctx, listChapters, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListChaptersRow{}
+	for rows.Next() {
+		var i ListChaptersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UserID,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.CategoryID,
+			&i.ParentID,
+			&i.TimeZone,
+			&i.ArchivedAt,
+			&i.MinReliability,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGroups = `-- name: ListGroups :many
+SELECT g.id, g.name, g.user_id, g.description, g.created_at, g.updated_at, g.deleted_at, g.category_id, g.parent_id, g.time_zone, g.archived_at, g.min_reliability FROM groups g
+LEFT JOIN categories c ON c.id = g.category_id
+WHERE g.deleted_at IS NULL
+  AND ($1::text IS NULL OR c.slug = $1)
+  AND ($2::text IS NULL OR EXISTS (
+      SELECT 1 FROM group_tags gt
+      JOIN tags t ON t.id = gt.tag_id
+      WHERE gt.group_id = g.id AND t.name = $2
+  ))
+ORDER BY g.id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListGroupsParams struct {
+	Category pgtype.Text `json:"category"`
+	Tag      pgtype.Text `json:"tag"`
+	Limit    int32       `json:"limit"`
+	Offset   int32       `json:"offset"`
+}
+
+func (q *Queries) ListGroups(ctx context.Context, arg ListGroupsParams) ([]Group, error) {
+	rows, err := q.db.Query(ctx, listGroups,
+		arg.Category,
+		arg.Tag,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Group{}
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UserID,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.CategoryID,
+			&i.ParentID,
+			&i.TimeZone,
+			&i.ArchivedAt,
+			&i.MinReliability,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedGroups = `-- name: PurgeDeletedGroups :execrows
+DELETE FROM groups
+WHERE deleted_at < $1::timestamp
+`
+
+// Hard deletes groups soft deleted before the cutoff, cascading to their members, events and RSVPs.
+func (q *Queries) PurgeDeletedGroups(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedGroups, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreGroup = `-- name: RestoreGroup :one
+UPDATE groups SET archived_at = NULL, deleted_at = NULL, updated_at = now()
+WHERE id = $1 AND (deleted_at IS NULL OR deleted_at > $2::timestamp)
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+type RestoreGroupParams struct {
+	ID           int64            `json:"id"`
+	DeletedAfter pgtype.Timestamp `json:"deleted_after"`
+}
+
+// Unarchives a group and undoes its soft deletion, provided it was deleted after the grace period cutoff.
+func (q *Queries) RestoreGroup(ctx context.Context, arg RestoreGroupParams) (Group, error) {
+	row := q.db.QueryRow(ctx, restoreGroup, arg.ID, arg.DeletedAfter)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
+const setGroupCategory = `-- name: SetGroupCategory :one
+UPDATE groups SET category_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+type SetGroupCategoryParams struct {
+	ID         int64       `json:"id"`
+	CategoryID pgtype.Int8 `json:"category_id"`
+}
+
+func (q *Queries) SetGroupCategory(ctx context.Context, arg SetGroupCategoryParams) (Group, error) {
+	row := q.db.QueryRow(ctx, setGroupCategory, arg.ID, arg.CategoryID)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
+const setGroupMinReliability = `-- name: SetGroupMinReliability :one
+UPDATE groups SET min_reliability = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+type SetGroupMinReliabilityParams struct {
+	ID             int64         `json:"id"`
+	MinReliability pgtype.Float8 `json:"min_reliability"`
+}
+
+func (q *Queries) SetGroupMinReliability(ctx context.Context, arg SetGroupMinReliabilityParams) (Group, error) {
+	row := q.db.QueryRow(ctx, setGroupMinReliability, arg.ID, arg.MinReliability)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
+const setGroupParent = `-- name: SetGroupParent :one
+UPDATE groups SET parent_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+type SetGroupParentParams struct {
+	ID       int64       `json:"id"`
+	ParentID pgtype.Int8 `json:"parent_id"`
+}
+
+func (q *Queries) SetGroupParent(ctx context.Context, arg SetGroupParentParams) (Group, error) {
+	row := q.db.QueryRow(ctx, setGroupParent, arg.ID, arg.ParentID)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
+const setGroupTimeZone = `-- name: SetGroupTimeZone :one
+UPDATE groups SET time_zone = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+type SetGroupTimeZoneParams struct {
+	ID       int64  `json:"id"`
+	TimeZone string `json:"time_zone"`
+}
+
+func (q *Queries) SetGroupTimeZone(ctx context.Context, arg SetGroupTimeZoneParams) (Group, error) {
+	row := q.db.QueryRow(ctx, setGroupTimeZone, arg.ID, arg.TimeZone)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
+
+const softDeleteGroup = `-- name: SoftDeleteGroup :one
+UPDATE groups SET deleted_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, user_id, description, created_at, updated_at, deleted_at, category_id, parent_id, time_zone, archived_at, min_reliability
+`
+
+func (q *Queries) SoftDeleteGroup(ctx context.Context, id int64) (Group, error) {
+	row := q.db.QueryRow(ctx, softDeleteGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UserID,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.CategoryID,
+		&i.ParentID,
+		&i.TimeZone,
+		&i.ArchivedAt,
+		&i.MinReliability,
+	)
+	return i, err
+}
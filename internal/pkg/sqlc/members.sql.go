@@ -12,17 +12,18 @@ import (
 )
 
 const createGroupMember = `-- name: CreateGroupMember :one
-INSERT INTO members (group_id, email, phone, name, user_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, email, phone, name, group_id, user_id, created_at, updated_at, deleted_at
+INSERT INTO members (group_id, email, phone, phone_e164, name, user_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, email, phone, name, group_id, user_id, created_at, updated_at, deleted_at, phone_e164
 `
 
 type CreateGroupMemberParams struct {
-	GroupID int64       `json:"group_id"`
-	Email   pgtype.Text `json:"email"`
-	Phone   string      `json:"phone"`
-	Name    string      `json:"name"`
-	UserID  pgtype.UUID `json:"user_id"`
+	GroupID   int64       `json:"group_id"`
+	Email     pgtype.Text `json:"email"`
+	Phone     string      `json:"phone"`
+	PhoneE164 pgtype.Text `json:"phone_e164"`
+	Name      string      `json:"name"`
+	UserID    pgtype.UUID `json:"user_id"`
 }
 
 func (q *Queries) CreateGroupMember(ctx context.Context, arg CreateGroupMemberParams) (Member, error) {
@@ -30,6 +31,7 @@ func (q *Queries) CreateGroupMember(ctx context.Context, arg CreateGroupMemberPa
 		arg.GroupID,
 		arg.Email,
 		arg.Phone,
+		arg.PhoneE164,
 		arg.Name,
 		arg.UserID,
 	)
@@ -44,6 +46,289 @@ func (q *Queries) CreateGroupMember(ctx context.Context, arg CreateGroupMemberPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.PhoneE164,
+	)
+	return i, err
+}
+
+const getGroupMember = `-- name: GetGroupMember :one
+SELECT id, email, phone, name, group_id, user_id, created_at, updated_at, deleted_at, phone_e164 FROM members
+WHERE user_id = $1 AND group_id = $2 AND deleted_at IS NULL
+`
+
+type GetGroupMemberParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	GroupID int64       `json:"group_id"`
+}
+
+func (q *Queries) GetGroupMember(ctx context.Context, arg GetGroupMemberParams) (Member, error) {
+	row := q.db.QueryRow(ctx, getGroupMember, arg.UserID, arg.GroupID)
+	var i Member
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Phone,
+		&i.Name,
+		&i.GroupID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.PhoneE164,
 	)
 	return i, err
 }
+
+const listGroupTreeMembers = `-- name: ListGroupTreeMembers :many
+WITH RECURSIVE tree AS (
+    SELECT id FROM groups WHERE groups.id = $1 AND deleted_at IS NULL
+    UNION
+    SELECT g.id FROM groups g
+    JOIN tree t ON g.parent_id = t.id
+    WHERE g.deleted_at IS NULL
+)
+SELECT m.id, m.group_id, m.user_id, m.email, m.phone, m.phone_e164,
+    COALESCE(p.display_name, m.name)::text AS name,
+    p.avatar_url, p.bio, p.city, p.interests,
+    m.created_at
+FROM members m
+JOIN tree t ON t.id = m.group_id
+LEFT JOIN profiles p ON p.user_id = m.user_id
+WHERE m.deleted_at IS NULL
+ORDER BY m.id
+LIMIT $2 OFFSET $3
+`
+
+type ListGroupTreeMembersParams struct {
+	ID     int64 `json:"id"`
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListGroupTreeMembersRow struct {
+	ID        int64            `json:"id"`
+	GroupID   int64            `json:"group_id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Email     pgtype.Text      `json:"email"`
+	Phone     string           `json:"phone"`
+	PhoneE164 pgtype.Text      `json:"phone_e164"`
+	Name      string           `json:"name"`
+	AvatarUrl pgtype.Text      `json:"avatar_url"`
+	Bio       pgtype.Text      `json:"bio"`
+	City      pgtype.Text      `json:"city"`
+	Interests []string         `json:"interests"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// Lists the members of a group and of all of its chapters, as presented by their profiles.
+func (q *Queries) ListGroupTreeMembers(ctx context.Context, arg ListGroupTreeMembersParams) ([]ListGroupTreeMembersRow, error) {
+	rows, err := q.db.Query(ctx, listGroupTreeMembers, arg.ID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListGroupTreeMembersRow{}
+	for rows.Next() {
+		var i ListGroupTreeMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.UserID,
+			&i.Email,
+			&i.Phone,
+			&i.PhoneE164,
+			&i.Name,
+			&i.AvatarUrl,
+			&i.Bio,
+			&i.City,
+			&i.Interests,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMembersWithUnnormalizedPhones = `-- name: ListMembersWithUnnormalizedPhones :many
+SELECT id, email, phone, name, group_id, user_id, created_at, updated_at, deleted_at, phone_e164 FROM members
+WHERE id > $1 AND phone <> '' AND phone_e164 IS NULL
+ORDER BY id
+LIMIT $2
+`
+
+type ListMembersWithUnnormalizedPhonesParams struct {
+	After int64 `json:"after"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListMembersWithUnnormalizedPhones(ctx context.Context, arg ListMembersWithUnnormalizedPhonesParams) ([]Member, error) {
+	rows, err := q.db.Query(ctx, listMembersWithUnnormalizedPhones, arg.After, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Member{}
+	for rows.Next() {
+		var i Member
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Phone,
+			&i.Name,
+			&i.GroupID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.PhoneE164,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserMemberships = `-- name: ListUserMemberships :many
+SELECT g.id, g.name, g.description, g.category_id, g.parent_id, g.archived_at,
+    m.id AS member_id,
+    m.created_at AS joined_at,
+    (CASE WHEN admin.is_admin THEN 'admin' ELSE 'member' END)::text AS role,
+    (
+        SELECT COUNT(*) FROM events e
+        WHERE e.group_id = g.id AND e.deleted_at IS NULL AND e.starts_at > now()
+    ) AS upcoming_events,
+    (
+        SELECT COUNT(*) FROM announcement_deliveries d
+        JOIN announcements a ON a.id = d.announcement_id AND a.deleted_at IS NULL
+        WHERE d.member_id = m.id AND d.channel = 'in_app' AND d.read_at IS NULL
+    ) AS unread_announcements
+FROM members m
+JOIN groups g ON g.id = m.group_id AND g.deleted_at IS NULL
+-- Admins of a parent group administer its chapters too, as IsUserGroupAdmin checks.
+CROSS JOIN LATERAL (
+    WITH RECURSIVE lineage AS (
+        SELECT g.id, g.parent_id
+        UNION
+        SELECT p.id, p.parent_id FROM groups p
+        JOIN lineage l ON p.id = l.parent_id
+    )
+    SELECT EXISTS(
+        SELECT 1 FROM lineage l
+        JOIN group_admins ga ON ga.group_id = l.id
+        JOIN members am ON am.id = ga.member_id
+        WHERE am.user_id = m.user_id
+    ) AS is_admin
+) admin
+WHERE m.user_id = $1
+  AND m.deleted_at IS NULL
+  AND ($2::text IS NULL OR ($2 = 'admin') = admin.is_admin)
+  AND ($3::text IS NULL OR g.name ILIKE '%' || $3 || '%')
+  AND ($4::bigint IS NULL OR m.id < $4)
+ORDER BY m.id DESC
+LIMIT $5
+`
+
+type ListUserMembershipsParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Role   pgtype.Text `json:"role"`
+	Search pgtype.Text `json:"search"`
+	Before pgtype.Int8 `json:"before"`
+	Limit  int32       `json:"limit"`
+}
+
+type ListUserMembershipsRow struct {
+	ID                  int64            `json:"id"`
+	Name                string           `json:"name"`
+	Description         pgtype.Text      `json:"description"`
+	CategoryID          pgtype.Int8      `json:"category_id"`
+	ParentID            pgtype.Int8      `json:"parent_id"`
+	ArchivedAt          pgtype.Timestamp `json:"archived_at"`
+	MemberID            int64            `json:"member_id"`
+	JoinedAt            pgtype.Timestamp `json:"joined_at"`
+	Role                string           `json:"role"`
+	UpcomingEvents      int64            `json:"upcoming_events"`
+	UnreadAnnouncements int64            `json:"unread_announcements"`
+}
+
+// Lists every group the user belongs to, newest membership first, with their role and what awaits them there.
+func (q *Queries) ListUserMemberships(ctx context.Context, arg ListUserMembershipsParams) ([]ListUserMembershipsRow, error) {
+	rows, err := q.db.Query(ctx, listUserMemberships,
+		arg.UserID,
+		arg.Role,
+		arg.Search,
+		arg.Before,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserMembershipsRow{}
+	for rows.Next() {
+		var i ListUserMembershipsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CategoryID,
+			&i.ParentID,
+			&i.ArchivedAt,
+			&i.MemberID,
+			&i.JoinedAt,
+			&i.Role,
+			&i.UpcomingEvents,
+			&i.UnreadAnnouncements,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMemberPhoneE164 = `-- name: SetMemberPhoneE164 :exec
+UPDATE members SET phone_e164 = $2
+WHERE id = $1
+`
+
+type SetMemberPhoneE164Params struct {
+	ID        int64       `json:"id"`
+	PhoneE164 pgtype.Text `json:"phone_e164"`
+}
+
+func (q *Queries) SetMemberPhoneE164(ctx context.Context, arg SetMemberPhoneE164Params) error {
+	_, err := q.db.Exec(ctx, setMemberPhoneE164, arg.ID, arg.PhoneE164)
+	return err
+}
+
+const updateUserPhone = `-- name: UpdateUserPhone :execrows
+UPDATE members SET phone = $1, phone_e164 = $2, updated_at = now()
+WHERE user_id = $3 AND deleted_at IS NULL
+`
+
+type UpdateUserPhoneParams struct {
+	Phone     string      `json:"phone"`
+	PhoneE164 pgtype.Text `json:"phone_e164"`
+	UserID    pgtype.UUID `json:"user_id"`
+}
+
+// Sets the contact phone of every membership of the user.
+func (q *Queries) UpdateUserPhone(ctx context.Context, arg UpdateUserPhoneParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserPhone, arg.Phone, arg.PhoneE164, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -8,6 +8,53 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Announcement struct {
+	ID             int64            `json:"id"`
+	GroupID        int64            `json:"group_id"`
+	EventID        pgtype.Int8      `json:"event_id"`
+	AuthorMemberID pgtype.Int8      `json:"author_member_id"`
+	Title          string           `json:"title"`
+	Body           string           `json:"body"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+}
+
+type AnnouncementDelivery struct {
+	ID             int64            `json:"id"`
+	AnnouncementID int64            `json:"announcement_id"`
+	MemberID       int64            `json:"member_id"`
+	Channel        string           `json:"channel"`
+	Status         string           `json:"status"`
+	Error          pgtype.Text      `json:"error"`
+	SentAt         pgtype.Timestamp `json:"sent_at"`
+	ReadAt         pgtype.Timestamp `json:"read_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	DeliverAfter   pgtype.Timestamp `json:"deliver_after"`
+}
+
+type ApiKey struct {
+	ID         int64            `json:"id"`
+	GroupID    int64            `json:"group_id"`
+	Name       string           `json:"name"`
+	Prefix     string           `json:"prefix"`
+	SecretHash []byte           `json:"secret_hash"`
+	Scopes     []string         `json:"scopes"`
+	CreatedBy  pgtype.UUID      `json:"created_by"`
+	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
+	LastUsedAt pgtype.Timestamp `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Category struct {
+	ID        int64            `json:"id"`
+	Slug      string           `json:"slug"`
+	Name      string           `json:"name"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type Event struct {
 	ID          int64            `json:"id"`
 	Title       pgtype.Text      `json:"title"`
@@ -20,16 +67,23 @@ type Event struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	StartsAt    pgtype.Timestamp `json:"starts_at"`
+	Visibility  string           `json:"visibility"`
 }
 
 type Group struct {
-	ID          int64            `json:"id"`
-	Name        string           `json:"name"`
-	UserID      pgtype.UUID      `json:"user_id"`
-	Description pgtype.Text      `json:"description"`
-	CreatedAt   pgtype.Timestamp `json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
-	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	ID             int64            `json:"id"`
+	Name           string           `json:"name"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	Description    pgtype.Text      `json:"description"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	CategoryID     pgtype.Int8      `json:"category_id"`
+	ParentID       pgtype.Int8      `json:"parent_id"`
+	TimeZone       string           `json:"time_zone"`
+	ArchivedAt     pgtype.Timestamp `json:"archived_at"`
+	MinReliability pgtype.Float8    `json:"min_reliability"`
 }
 
 type GroupAdmin struct {
@@ -41,6 +95,18 @@ type GroupAdmin struct {
 	DeletedAt pgtype.Timestamp `json:"deleted_at"`
 }
 
+type GroupRedirect struct {
+	OldGroupID int64            `json:"old_group_id"`
+	GroupID    int64            `json:"group_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type GroupTag struct {
+	GroupID   int64            `json:"group_id"`
+	TagID     int64            `json:"tag_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type Member struct {
 	ID        int64            `json:"id"`
 	Email     pgtype.Text      `json:"email"`
@@ -51,6 +117,67 @@ type Member struct {
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 	DeletedAt pgtype.Timestamp `json:"deleted_at"`
+	PhoneE164 pgtype.Text      `json:"phone_e164"`
+}
+
+type MemberAttendance struct {
+	MemberID          int64       `json:"member_id"`
+	UserID            pgtype.UUID `json:"user_id"`
+	GroupID           int64       `json:"group_id"`
+	Rsvps             int64       `json:"rsvps"`
+	Attended          int64       `json:"attended"`
+	NoShows           int64       `json:"no_shows"`
+	LateCancellations int64       `json:"late_cancellations"`
+}
+
+type NotificationPreference struct {
+	ID        int64            `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	GroupID   pgtype.Int8      `json:"group_id"`
+	Category  string           `json:"category"`
+	Channel   string           `json:"channel"`
+	Enabled   bool             `json:"enabled"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type NotificationSetting struct {
+	UserID          pgtype.UUID      `json:"user_id"`
+	TimeZone        string           `json:"time_zone"`
+	QuietHoursStart pgtype.Time      `json:"quiet_hours_start"`
+	QuietHoursEnd   pgtype.Time      `json:"quiet_hours_end"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type PlatformAdmin struct {
+	UserID    pgtype.UUID      `json:"user_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type PrivacyRequest struct {
+	ID        int64            `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Kind      string           `json:"kind"`
+	Details   []byte           `json:"details"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Profile struct {
+	UserID      pgtype.UUID      `json:"user_id"`
+	DisplayName string           `json:"display_name"`
+	Bio         pgtype.Text      `json:"bio"`
+	AvatarUrl   pgtype.Text      `json:"avatar_url"`
+	City        pgtype.Text      `json:"city"`
+	Interests   []string         `json:"interests"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string           `json:"key"`
+	Tokens    float64          `json:"tokens"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
 
 type Rsvp struct {
@@ -63,4 +190,20 @@ type Rsvp struct {
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
 	DeletedAt          pgtype.Timestamp `json:"deleted_at"`
+	Attendance         pgtype.Text      `json:"attendance"`
+}
+
+type SessionRevocation struct {
+	ID        int64            `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	SessionID pgtype.UUID      `json:"session_id"`
+	Reason    pgtype.Text      `json:"reason"`
+	RevokedBy pgtype.UUID      `json:"revoked_by"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+}
+
+type Tag struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
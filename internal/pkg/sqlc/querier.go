@@ -6,14 +6,152 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddGroupTags(ctx context.Context, arg AddGroupTagsParams) error
+	// Keeps an anonymized profile rather than deleting it, so it is not recreated from the token claims.
+	AnonymizeProfile(ctx context.Context, userID pgtype.UUID) error
+	// Scrubs the contact details of every membership of the user. The rows are kept, soft deleted, so the
+	// RSVPs and payments that reference them stay intact for accounting.
+	AnonymizeUserMembers(ctx context.Context, userID pgtype.UUID) (int64, error)
+	ArchiveGroup(ctx context.Context, id int64) (Group, error)
+	// Withdraws an RSVP, recording it as a late cancellation when attendance is set.
+	CancelRsvp(ctx context.Context, arg CancelRsvpParams) (Rsvp, error)
+	ClaimAnonymousDeliveries(ctx context.Context, arg ClaimAnonymousDeliveriesParams) error
+	// Points the RSVPs of an anonymous user at the memberships the user already has in the same groups, unless they RSVPed to the event themselves.
+	ClaimAnonymousRsvps(ctx context.Context, arg ClaimAnonymousRsvpsParams) error
+	// Claims the deliveries that are due, oldest first, so no other instance sends them too. Claimed
+	// deliveries are sending until lease_until, after which those whose outcome was never recorded are claimed
+	// again.
+	ClaimDueAnnouncementDeliveries(ctx context.Context, arg ClaimDueAnnouncementDeliveriesParams) ([]ClaimDueAnnouncementDeliveriesRow, error)
+	ClearUserGroupOwnership(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CreateAnnouncement(ctx context.Context, arg CreateAnnouncementParams) (Announcement, error)
+	CreateAnnouncementDeliveries(ctx context.Context, arg CreateAnnouncementDeliveriesParams) ([]AnnouncementDelivery, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (CreateApiKeyRow, error)
 	CreateGroup(ctx context.Context, arg CreateGroupParams) (Group, error)
 	CreateGroupAdmin(ctx context.Context, arg CreateGroupAdminParams) (GroupAdmin, error)
 	CreateGroupMember(ctx context.Context, arg CreateGroupMemberParams) (Member, error)
+	// Redirects the old group to its replacement, along with any group that was already redirected to it.
+	CreateGroupRedirect(ctx context.Context, arg CreateGroupRedirectParams) error
+	CreatePrivacyRequest(ctx context.Context, arg CreatePrivacyRequestParams) (PrivacyRequest, error)
+	// Creates the profile or returns the existing one when a concurrent request got there first.
+	CreateProfile(ctx context.Context, arg CreateProfileParams) (Profile, error)
+	CreateRsvp(ctx context.Context, arg CreateRsvpParams) (Rsvp, error)
+	CreateSessionRevocation(ctx context.Context, arg CreateSessionRevocationParams) (SessionRevocation, error)
+	// Deletes the anonymous memberships in groups the user already belongs to. Run it after the claims.
+	DeleteClaimedAnonymousMembers(ctx context.Context, arg DeleteClaimedAnonymousMembersParams) (int64, error)
+	// Deletes the source memberships of users who are already target members. Run it after the remaps.
+	DeleteDuplicateMembers(ctx context.Context, arg DeleteDuplicateMembersParams) (int64, error)
+	DeleteGroupAdmins(ctx context.Context, groupID int64) error
+	DeleteGroupTags(ctx context.Context, groupID int64) error
+	DeleteNotificationPreference(ctx context.Context, arg DeleteNotificationPreferenceParams) error
+	DeleteUserGroupAdmins(ctx context.Context, userID pgtype.UUID) (int64, error)
+	DeleteUserNotificationPreferences(ctx context.Context, userID pgtype.UUID) (int64, error)
+	DeleteUserNotificationSettings(ctx context.Context, userID pgtype.UUID) error
+	GetAnnouncement(ctx context.Context, id int64) (Announcement, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetCategory(ctx context.Context, id int64) (Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (Category, error)
+	GetEvent(ctx context.Context, id int64) (Event, error)
+	// Aggregates events held, RSVP conversion, no-shows and revenue per bucket.
+	GetEventStats(ctx context.Context, arg GetEventStatsParams) ([]GetEventStatsRow, error)
+	GetGroup(ctx context.Context, id int64) (Group, error)
+	GetGroupMember(ctx context.Context, arg GetGroupMemberParams) (Member, error)
+	GetGroupRedirect(ctx context.Context, oldGroupID int64) (int64, error)
+	// Counts members joining per bucket along with the running member total.
+	GetMemberGrowth(ctx context.Context, arg GetMemberGrowthParams) ([]GetMemberGrowthRow, error)
+	GetMemberRsvp(ctx context.Context, arg GetMemberRsvpParams) (Rsvp, error)
+	GetMostEngagedMembers(ctx context.Context, arg GetMostEngagedMembersParams) ([]GetMostEngagedMembersRow, error)
+	GetNotificationSettings(ctx context.Context, userID pgtype.UUID) (NotificationSetting, error)
+	GetProfile(ctx context.Context, userID pgtype.UUID) (Profile, error)
+	// Sums the attendance history of every membership of the user, including those the caller cannot see.
+	GetUserAttendance(ctx context.Context, userID pgtype.UUID) (GetUserAttendanceRow, error)
 	GetUserGrops(ctx context.Context, arg GetUserGropsParams) ([]Group, error)
 	IsGroupAdmin(ctx context.Context, arg IsGroupAdminParams) (bool, error)
+	IsPlatformAdmin(ctx context.Context, userID pgtype.UUID) (bool, error)
+	// Admins of a parent group inherit admin rights over all of its chapters.
+	IsUserGroupAdmin(ctx context.Context, arg IsUserGroupAdminParams) (bool, error)
+	ListAnnouncementDeliveries(ctx context.Context, announcementID int64) ([]AnnouncementDelivery, error)
+	ListAnnouncementRecipients(ctx context.Context, arg ListAnnouncementRecipientsParams) ([]Member, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	ListChapters(ctx context.Context, parentID pgtype.Int8) ([]ListChaptersRow, error)
+	// Lists the RSVPs to an event with each member's attendance history in the group and across the platform.
+	ListEventAttendees(ctx context.Context, eventID int64) ([]ListEventAttendeesRow, error)
+	ListGroupApiKeys(ctx context.Context, groupID int64) ([]ListGroupApiKeysRow, error)
+	ListGroupTags(ctx context.Context, groupID int64) ([]Tag, error)
+	// Lists the events of a group and of all of its chapters.
+	ListGroupTreeEvents(ctx context.Context, arg ListGroupTreeEventsParams) ([]Event, error)
+	// Lists the members of a group and of all of its chapters, as presented by their profiles.
+	ListGroupTreeMembers(ctx context.Context, arg ListGroupTreeMembersParams) ([]ListGroupTreeMembersRow, error)
+	ListGroups(ctx context.Context, arg ListGroupsParams) ([]Group, error)
+	// Lists the announcements delivered in-app to a user within a group, newest first.
+	ListMemberAnnouncements(ctx context.Context, arg ListMemberAnnouncementsParams) ([]ListMemberAnnouncementsRow, error)
+	ListMembersWithUnnormalizedPhones(ctx context.Context, arg ListMembersWithUnnormalizedPhonesParams) ([]Member, error)
+	// Lists the overrides of the users that apply to a group, user-wide ones first. Without a group only the
+	// user-wide overrides are returned.
+	ListNotificationPreferences(ctx context.Context, arg ListNotificationPreferencesParams) ([]NotificationPreference, error)
+	ListNotificationSettings(ctx context.Context, userIds []pgtype.UUID) ([]NotificationSetting, error)
+	ListPopularTags(ctx context.Context, limit int32) ([]ListPopularTagsRow, error)
+	ListSessionRevocations(ctx context.Context, since pgtype.Timestamp) ([]SessionRevocation, error)
+	// Lists the groups the user is the only admin of, which erasing their account would leave without one.
+	ListSoleAdminGroups(ctx context.Context, userID pgtype.UUID) ([]ListSoleAdminGroupsRow, error)
+	// Lists the announcements the user received on every channel.
+	ListUserAnnouncementDeliveries(ctx context.Context, userID pgtype.UUID) ([]ListUserAnnouncementDeliveriesRow, error)
+	// Lists the announcements the user authored.
+	ListUserAnnouncements(ctx context.Context, userID pgtype.UUID) ([]Announcement, error)
+	ListUserMembers(ctx context.Context, userID pgtype.UUID) ([]Member, error)
+	// Lists every group the user belongs to, newest membership first, with their role and what awaits them there.
+	ListUserMemberships(ctx context.Context, arg ListUserMembershipsParams) ([]ListUserMembershipsRow, error)
+	ListUserRsvps(ctx context.Context, userID pgtype.UUID) ([]Rsvp, error)
+	MarkAnnouncementRead(ctx context.Context, arg MarkAnnouncementReadParams) (int64, error)
+	// Makes every source admin an admin of the target, through their target membership when they already have one.
+	MergeGroupAdmins(ctx context.Context, arg MergeGroupAdminsParams) error
+	MoveGroupAnnouncements(ctx context.Context, arg MoveGroupAnnouncementsParams) error
+	MoveGroupChapters(ctx context.Context, arg MoveGroupChaptersParams) error
+	MoveGroupEvents(ctx context.Context, arg MoveGroupEventsParams) (int64, error)
+	MoveGroupMembers(ctx context.Context, arg MoveGroupMembersParams) (int64, error)
+	// Deletes buckets untouched since before, which have refilled and are no different from missing ones.
+	PruneRateLimitBuckets(ctx context.Context, before pgtype.Timestamp) (int64, error)
+	// Deletes revocations older than any token they could still reject.
+	PruneSessionRevocations(ctx context.Context, before pgtype.Timestamp) (int64, error)
+	// Hard deletes groups soft deleted before the cutoff, cascading to their members, events and RSVPs.
+	PurgeDeletedGroups(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error)
+	// Hands the remaining memberships of an anonymous user, along with their RSVPs, to the user.
+	ReassignAnonymousMembers(ctx context.Context, arg ReassignAnonymousMembersParams) (int64, error)
+	RemapDuplicateMemberAuthors(ctx context.Context, arg RemapDuplicateMemberAuthorsParams) error
+	RemapDuplicateMemberDeliveries(ctx context.Context, arg RemapDuplicateMemberDeliveriesParams) error
+	// Points the RSVPs of source members who are also target members at their target membership.
+	RemapDuplicateMemberRsvps(ctx context.Context, arg RemapDuplicateMemberRsvpsParams) error
+	RescheduleAnnouncementDelivery(ctx context.Context, arg RescheduleAnnouncementDeliveryParams) error
+	// Unarchives a group and undoes its soft deletion, provided it was deleted after the grace period cutoff.
+	RestoreGroup(ctx context.Context, arg RestoreGroupParams) (Group, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (RevokeApiKeyRow, error)
+	SetGroupCategory(ctx context.Context, arg SetGroupCategoryParams) (Group, error)
+	SetGroupMinReliability(ctx context.Context, arg SetGroupMinReliabilityParams) (Group, error)
+	SetGroupParent(ctx context.Context, arg SetGroupParentParams) (Group, error)
+	SetGroupTimeZone(ctx context.Context, arg SetGroupTimeZoneParams) (Group, error)
+	SetMemberPhoneE164(ctx context.Context, arg SetMemberPhoneE164Params) error
+	// Assumes the role of the signed in user and exposes their claims to row-level security policies until the transaction ends.
+	SetRequestClaims(ctx context.Context, arg SetRequestClaimsParams) error
+	// Records the outcome of an active RSVP. Cancelled RSVPs keep the outcome of their cancellation.
+	SetRsvpAttendance(ctx context.Context, arg SetRsvpAttendanceParams) (Rsvp, error)
+	SoftDeleteGroup(ctx context.Context, id int64) (Group, error)
+	// Refills the bucket of key at rate tokens a second up to capacity and takes a token from it. A negative result means the bucket was empty: the request is denied and the bucket holds one token more than the result.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (float64, error)
+	// Records that a key was used, at most once a minute so busy integrations do not write on every request.
+	TouchApiKey(ctx context.Context, id int64) error
+	UpdateAnnouncementDelivery(ctx context.Context, arg UpdateAnnouncementDeliveryParams) error
+	// Only updates the fields that are set. An empty bio, avatar or city clears it, while the display name
+	// cannot be cleared.
+	UpdateProfile(ctx context.Context, arg UpdateProfileParams) (Profile, error)
+	// Sets the contact phone of every membership of the user.
+	UpdateUserPhone(ctx context.Context, arg UpdateUserPhoneParams) (int64, error)
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) error
+	UpsertNotificationSettings(ctx context.Context, arg UpsertNotificationSettingsParams) (NotificationSetting, error)
+	UpsertTags(ctx context.Context, names []string) ([]Tag, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -3,23 +3,134 @@ package sqlc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/metrics"
 )
 
+// maxTransactionRetries is how often ExecuteTransaction retries a transaction the database aborted.
+const maxTransactionRetries = 3
+
+type txContextKey struct{}
+
+// ContextTx is a transaction carried by a context. Queries the Store issues with that context run in it.
+type ContextTx struct {
+	pgx.Tx
+	afterCommit []func()
+}
+
+// Commit commits the transaction and then runs the functions registered with AfterCommit.
+func (t *ContextTx) Commit(ctx context.Context) error {
+	if err := t.Tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, f := range t.afterCommit {
+		f()
+	}
+
+	return nil
+}
+
+// AfterCommit runs f once the transaction carried by ctx commits, and not at all if it is rolled back.
+// Without a transaction f runs right away.
+func AfterCommit(ctx context.Context, f func()) {
+	if tx, _ := ctx.Value(txContextKey{}).(*ContextTx); tx != nil {
+		tx.afterCommit = append(tx.afterCommit, f)
+		return
+	}
+	f()
+}
+
+// WithoutTx returns a copy of ctx whose queries no longer run in the transaction it carries, for work that
+// outlives the transaction.
+func WithoutTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txContextKey{}, (*ContextTx)(nil))
+}
+
+// contextDB runs queries in the transaction carried by their context, and on the pool otherwise.
+type contextDB struct {
+	pool *pgxpool.Pool
+}
+
+func (db contextDB) conn(ctx context.Context) DBTX {
+	if tx, _ := ctx.Value(txContextKey{}).(*ContextTx); tx != nil {
+		return tx.Tx
+	}
+	return db.pool
+}
+
+func (db contextDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return db.conn(ctx).Exec(ctx, sql, args...)
+}
+
+func (db contextDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return db.conn(ctx).Query(ctx, sql, args...)
+}
+
+func (db contextDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return db.conn(ctx).QueryRow(ctx, sql, args...)
+}
+
 type Store struct {
 	*Queries
 	pool *pgxpool.Pool
 }
 
-func (s *Store) ExecuteTransaction(ctx context.Context, f func() error) error {
-	tx, err := s.pool.Begin(ctx)
+// BeginContext begins a transaction and returns a copy of ctx carrying it, so every query the Store
+// issues with that context runs in the transaction until it is committed or rolled back.
+func (s *Store) BeginContext(ctx context.Context) (context.Context, *ContextTx, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	contextTx := &ContextTx{Tx: tx}
+
+	return context.WithValue(ctx, txContextKey{}, contextTx), contextTx, nil
+}
+
+// ExecuteTransaction runs f inside a database transaction. Queries issued through the provided
+// *Queries are bound to the transaction; it is committed when f returns nil and rolled back otherwise.
+// Transactions aborted by a serialization failure or a deadlock are retried up to maxTransactionRetries
+// times, so f may run more than once. Within a transaction carried by ctx, f runs in a savepoint of it,
+// which is not retried as such failures abort the whole transaction.
+func (s *Store) ExecuteTransaction(ctx context.Context, f func(q *Queries) error) error {
+	if tx, _ := ctx.Value(txContextKey{}).(*ContextTx); tx != nil {
+		return s.executeTransaction(ctx, f)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := s.executeTransaction(ctx, f)
+
+		var pgErr *pgconn.PgError
+		if attempt == maxTransactionRetries || ctx.Err() != nil || !errors.As(err, &pgErr) {
+			return err
+		}
+
+		switch pgErr.Code {
+		case internal.SerializationFailureCode:
+			metrics.TransactionRetries.WithLabelValues("serialization_failure").Inc()
+		case internal.DeadlockDetectedCode:
+			metrics.TransactionRetries.WithLabelValues("deadlock").Inc()
+		default:
+			return err
+		}
+	}
+}
+
+func (s *Store) executeTransaction(ctx context.Context, f func(q *Queries) error) error {
+	tx, err := s.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
 
-	if err := f(); err != nil {
+	if err := f(s.WithTx(tx)); err != nil {
 		if rollBackErr := tx.Rollback(ctx); rollBackErr != nil {
 			return fmt.Errorf("executing provided function: %w, rolling back transaction: %w", err, rollBackErr)
 		}
@@ -34,9 +145,63 @@ func (s *Store) ExecuteTransaction(ctx context.Context, f func() error) error {
 
 }
 
+// Listen subscribes to the notification channel on a connection of its own and calls f with the payload
+// of every notification until ctx is done or the connection fails, returning the error that ended it.
+// Notifications sent while nobody listens are lost, so callers that reconnect should catch up first.
+func (s *Store) Listen(ctx context.Context, channel string, f func(payload string)) error {
+	pooled, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	// The connection keeps listening until it is closed, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listening on %s: %w", channel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("waiting for notification on %s: %w", channel, err)
+		}
+		f(notification.Payload)
+	}
+}
+
+// Ping checks that the database can be reached.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// MigrationVersion returns the version golang-migrate recorded the schema at, and whether the migration to
+// it failed half way. A database that was never migrated is at version 0.
+func (s *Store) MigrationVersion(ctx context.Context) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := s.pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || errors.As(err, &pgErr) && pgErr.Code == internal.UndefinedTableCode {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+
+	return uint64(version), dirty, nil
+}
+
+func (s *Store) begin(ctx context.Context) (pgx.Tx, error) {
+	if tx, _ := ctx.Value(txContextKey{}).(*ContextTx); tx != nil {
+		return tx.Begin(ctx)
+	}
+	return s.pool.Begin(ctx)
+}
+
 func NewStore(pool *pgxpool.Pool) *Store {
 	return &Store{
-		Queries: New(pool),
+		Queries: New(contextDB{pool: pool}),
 		pool:    pool,
 	}
 }
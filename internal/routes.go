@@ -4,13 +4,61 @@ package internal
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
 var (
-	APIVersion = "/api/v1"
-	Group      = createRoute(http.MethodPost, "group")
-	Profile    = createRoute(http.MethodGet, "/profile")
+	APIVersion     = "/api/v1"
+	Group          = createRoute(http.MethodPost, "group")
+	Profile        = createRoute(http.MethodGet, "/profile")
+	UpdateProfile  = createRoute(http.MethodPatch, "/profile")
+	MyGroups       = createRoute(http.MethodGet, "me/groups")
+	ExportData     = createRoute(http.MethodGet, "me/export")
+	EraseAccount   = createRoute(http.MethodPost, "me/erasure")
+	Groups         = createRoute(http.MethodGet, "groups")
+	GroupDetails   = createRoute(http.MethodGet, "groups/{id}")
+	GroupCategory  = createRoute(http.MethodPut, "groups/{id}/category")
+	GroupTags      = createRoute(http.MethodPut, "groups/{id}/tags")
+	GroupParent    = createRoute(http.MethodPut, "groups/{id}/parent")
+	GroupChapters  = createRoute(http.MethodGet, "groups/{id}/chapters")
+	GroupEvents    = createRoute(http.MethodGet, "groups/{id}/events")
+	GroupMembers   = createRoute(http.MethodGet, "groups/{id}/members")
+	GroupAnalytics = createRoute(http.MethodGet, "groups/{id}/analytics")
+	GroupTimeZone  = createRoute(http.MethodPut, "groups/{id}/time-zone")
+	ArchiveGroup   = createRoute(http.MethodPost, "groups/{id}/archive")
+	RestoreGroup   = createRoute(http.MethodPost, "groups/{id}/restore")
+	DeleteGroup    = createRoute(http.MethodDelete, "groups/{id}")
+	MergeGroup     = createRoute(http.MethodPost, "groups/{id}/merge")
+	Categories     = createRoute(http.MethodGet, "categories")
+	PopularTags    = createRoute(http.MethodGet, "tags/popular")
+
+	CreateRsvp           = createRoute(http.MethodPost, "groups/{id}/events/{eventID}/rsvps")
+	CancelRsvp           = createRoute(http.MethodDelete, "groups/{id}/events/{eventID}/rsvps")
+	EventAttendees       = createRoute(http.MethodGet, "groups/{id}/events/{eventID}/attendees")
+	RsvpAttendance       = createRoute(http.MethodPut, "groups/{id}/rsvps/{rsvpID}/attendance")
+	ReliabilityThreshold = createRoute(http.MethodPut, "groups/{id}/reliability-threshold")
+	ClaimAnonymousRsvps  = createRoute(http.MethodPost, "me/rsvps/claim")
+
+	CreateAnnouncement     = createRoute(http.MethodPost, "groups/{id}/announcements")
+	Announcements          = createRoute(http.MethodGet, "groups/{id}/announcements")
+	AnnouncementDeliveries = createRoute(http.MethodGet, "groups/{id}/announcements/{announcementID}/deliveries")
+	AnnouncementRead       = createRoute(http.MethodPost, "announcements/{id}/read")
+
+	NotificationPreferences            = createRoute(http.MethodGet, "me/notification-preferences")
+	UpdateNotificationPreferences      = createRoute(http.MethodPut, "me/notification-preferences")
+	GroupNotificationPreferences       = createRoute(http.MethodGet, "groups/{id}/notification-preferences")
+	UpdateGroupNotificationPreferences = createRoute(http.MethodPut, "groups/{id}/notification-preferences")
+
+	APIKeys      = createRoute(http.MethodGet, "groups/{id}/api-keys")
+	CreateAPIKey = createRoute(http.MethodPost, "groups/{id}/api-keys")
+	RevokeAPIKey = createRoute(http.MethodDelete, "groups/{id}/api-keys/{keyID}")
+
+	RevokeSessions = createRoute(http.MethodPost, "admin/users/{userID}/session-revocations")
+
+	// Probes are served at the root, outside of the API version, where load balancers look for them.
+	Liveness  = http.MethodGet + " /healthz"
+	Readiness = http.MethodGet + " /readyz"
 )
 
 func createRoute(method, path string) string {
@@ -19,3 +67,13 @@ func createRoute(method, path string) string {
 	}
 	return fmt.Sprintf("%s %s/%s", method, APIVersion, path)
 }
+
+// PathID parses the named path wildcard of r as a positive database identifier.
+func PathID(r *http.Request, name string) (int64, error) {
+	value := r.PathValue(name)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidRequest, name, value)
+	}
+	return id, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/Oudwins/zog"
 	"github.com/Oudwins/zog/zconst"
@@ -40,3 +41,21 @@ func Validate[T any](schema *zog.StructSchema, body io.Reader) (result T, err er
 
 	return result, nil
 }
+
+// ValidPhone returns a zog test that accepts phone numbers NormalizePhone can normalize for region.
+func ValidPhone(region string) func(phone *string, ctx zog.Ctx) bool {
+	return func(phone *string, ctx zog.Ctx) bool {
+		_, err := NormalizePhone(*phone, region)
+		return err == nil
+	}
+}
+
+// ValidTimeZone is a zog test that accepts IANA time zone names. Go resolves "Local" to the zone of the
+// server, which the database does not know, so it is rejected.
+func ValidTimeZone(tz *string, ctx zog.Ctx) bool {
+	if *tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(*tz)
+	return err == nil
+}
@@ -3,23 +3,33 @@ package members
 
 import (
 	"cmp"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/Oudwins/zog"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/ship-labs/meet-loop-api/internal"
 	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/metrics"
 	"github.com/ship-labs/meet-loop-api/middleware"
+	"github.com/ship-labs/meet-loop-api/profiles"
 )
 
 const (
-	defaultLimit = 20
+	defaultLimit   = 20
+	maxLimit       = 100
+	phoneBatchSize = 500
 )
 
+var roles = []string{"admin", "member"}
+
 func GetUserProfile(store *sqlc.Store) middleware.Handler {
 	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
 		limitParam := r.URL.Query().Get("limit")
@@ -31,9 +41,9 @@ func GetUserProfile(store *sqlc.Store) middleware.Handler {
 			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
 		}
 
-		claims, err := middleware.GetClaims(r.Context())
+		profile, err := profiles.Ensure(r.Context(), store)
 		if err != nil {
-			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+			return middleware.Error(err)
 		}
 
 		groups, err := store.GetUserGrops(r.Context(), sqlc.GetUserGropsParams{
@@ -45,7 +55,7 @@ func GetUserProfile(store *sqlc.Store) middleware.Handler {
 		}
 
 		data := map[string]any{
-			"user":   claims,
+			"user":   profile,
 			"groups": groups,
 		}
 
@@ -56,7 +66,9 @@ func GetUserProfile(store *sqlc.Store) middleware.Handler {
 	}
 }
 
-func CreateGroup(store *sqlc.Store) middleware.Handler {
+// CreateGroup creates a group with the caller as its first member and admin. The caller's phone number
+// from their token is normalized to E.164 using phoneRegion when it has no country code.
+func CreateGroup(store *sqlc.Store, phoneRegion string) middleware.Handler {
 	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
 		type Body struct {
 			GroupName        string `json:"group_name" zog:"group_name"`
@@ -90,8 +102,19 @@ func CreateGroup(store *sqlc.Store) middleware.Handler {
 			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
 		}
 
-		transactionError := store.ExecuteTransaction(r.Context(), func() error {
-			group, err = store.CreateGroup(r.Context(), sqlc.CreateGroupParams{
+		profile, err := profiles.Ensure(r.Context(), store)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		// A malformed phone in the token metadata should not stop anyone from creating a group.
+		var phoneE164 pgtype.Text
+		if phone, err := internal.NormalizePhone(user.Phone, phoneRegion); err == nil {
+			phoneE164 = pgtype.Text{String: phone, Valid: true}
+		}
+
+		transactionError := store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			group, err = q.CreateGroup(r.Context(), sqlc.CreateGroupParams{
 				Name: body.GroupName,
 				Description: pgtype.Text{
 					String: body.GroupDescription,
@@ -103,21 +126,22 @@ func CreateGroup(store *sqlc.Store) middleware.Handler {
 				return fmt.Errorf("creating group: %w", err)
 			}
 
-			member, err = store.CreateGroupMember(r.Context(), sqlc.CreateGroupMemberParams{
+			member, err = q.CreateGroupMember(r.Context(), sqlc.CreateGroupMemberParams{
 				GroupID: group.ID,
 				Email: pgtype.Text{
 					String: user.Email,
 					Valid:  true,
 				},
-				Phone:  user.Phone,
-				Name:   user.Name,
-				UserID: userID,
+				Phone:     user.Phone,
+				PhoneE164: phoneE164,
+				Name:      profile.DisplayName,
+				UserID:    userID,
 			})
 			if err != nil {
 				return fmt.Errorf("creating group member: %w", err)
 			}
 
-			_, err = store.CreateGroupAdmin(r.Context(), sqlc.CreateGroupAdminParams{
+			_, err = q.CreateGroupAdmin(r.Context(), sqlc.CreateGroupAdminParams{
 				GroupID:  group.ID,
 				MemberID: member.ID,
 			})
@@ -138,6 +162,8 @@ func CreateGroup(store *sqlc.Store) middleware.Handler {
 			return middleware.Error(fmt.Errorf("onboarding user: %w", err))
 		}
 
+		sqlc.AfterCommit(r.Context(), metrics.GroupsCreated.Inc)
+
 		return middleware.JSON(middleware.Response{
 			Message: http.StatusText(http.StatusCreated),
 			Data: map[string]any{
@@ -147,3 +173,107 @@ func CreateGroup(store *sqlc.Store) middleware.Handler {
 		})
 	}
 }
+
+// ListMyGroups returns every group the caller is a member of, newest membership first, along with their
+// role, join date, upcoming event count and unread announcement count. Admins of a parent group are
+// admins of its chapters too. Filter with ?role= (admin or member) and ?q=, and pass the returned
+// next_cursor as ?before= to fetch the following page.
+func ListMyGroups(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		query := r.URL.Query()
+
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		limit = min(cmp.Or(max(limit, 0), defaultLimit), maxLimit)
+
+		role := query.Get("role")
+		if role != "" && !slices.Contains(roles, role) {
+			return middleware.Error(fmt.Errorf("%w: invalid role %q", internal.ErrInvalidRequest, role))
+		}
+
+		var before pgtype.Int8
+		if cursor := query.Get("before"); cursor != "" {
+			id, err := strconv.ParseInt(cursor, 10, 64)
+			if err != nil {
+				return middleware.Error(fmt.Errorf("%w: invalid cursor %q", internal.ErrInvalidRequest, cursor))
+			}
+			before = pgtype.Int8{Int64: id, Valid: true}
+		}
+
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		search := strings.TrimSpace(query.Get("q"))
+
+		memberships, err := store.ListUserMemberships(r.Context(), sqlc.ListUserMembershipsParams{
+			UserID: userID,
+			Role:   pgtype.Text{String: role, Valid: role != ""},
+			Search: pgtype.Text{String: search, Valid: search != ""},
+			Before: before,
+			Limit:  int32(limit),
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing user groups: %w", err))
+		}
+
+		var nextCursor *int64
+		if len(memberships) == limit {
+			nextCursor = &memberships[len(memberships)-1].MemberID
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data: map[string]any{
+				"groups":      memberships,
+				"next_cursor": nextCursor,
+			},
+		})
+	}
+}
+
+// NormalizePhones backfills the E.164 phone of members whose phone was stored before phones were normalized,
+// assuming region for numbers without a country code. Numbers that cannot be parsed are left unnormalized.
+func NormalizePhones(ctx context.Context, store *sqlc.Store, region string) {
+	var after int64
+	var normalized int
+
+	for {
+		members, err := store.ListMembersWithUnnormalizedPhones(ctx, sqlc.ListMembersWithUnnormalizedPhonesParams{
+			After: after,
+			Limit: phoneBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "members", "message", "listing members to normalize phones", "error", err)
+			}
+			return
+		}
+
+		for _, member := range members {
+			after = member.ID
+
+			phone, err := internal.NormalizePhone(member.Phone, region)
+			if err != nil {
+				continue
+			}
+
+			if err := store.SetMemberPhoneE164(ctx, sqlc.SetMemberPhoneE164Params{
+				ID:        member.ID,
+				PhoneE164: pgtype.Text{String: phone, Valid: true},
+			}); err != nil {
+				slog.ErrorContext(ctx, "members", "message", "normalizing phone", "memberID", member.ID, "error", err)
+				continue
+			}
+			normalized++
+		}
+
+		if len(members) < phoneBatchSize {
+			break
+		}
+	}
+
+	if normalized > 0 {
+		slog.InfoContext(ctx, "members", "message", "normalized member phones", "count", normalized)
+	}
+}
@@ -0,0 +1,170 @@
+// Package metrics exposes the Prometheus metrics of the API: its HTTP traffic, its database pool and
+// business events.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "meetloop"
+
+// unmatchedRoute labels requests no route matched, so unknown URLs cannot add series.
+const unmatchedRoute = "unmatched"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route pattern and status code.",
+	}, []string{"route", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	requestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+
+	// GroupsCreated counts the groups created.
+	GroupsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "groups_created_total",
+		Help:      "Groups created.",
+	})
+
+	// RsvpsCreated counts the RSVPs made, by whether the event is paid.
+	RsvpsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rsvps_created_total",
+		Help:      "RSVPs made, by whether the event is paid.",
+	}, []string{"paid"})
+
+	// RsvpsCancelled counts the RSVPs withdrawn, by whether they were withdrawn late.
+	RsvpsCancelled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rsvps_cancelled_total",
+		Help:      "RSVPs withdrawn, by whether they were withdrawn within a day of the event.",
+	}, []string{"late"})
+
+	// Payments counts the payments of RSVPs to paid events, by status: pending when the RSVP is made,
+	// then refund_due when a paid RSVP is withdrawn or abandoned when an unpaid one is.
+	Payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Payments of RSVPs to paid events, by status.",
+	}, []string{"status"})
+
+	// TransactionRetries counts the transactions retried after the database aborted them, by reason.
+	TransactionRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_retries_total",
+		Help:      "Transactions retried after a serialization failure or deadlock, by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests,
+		requestDuration,
+		requestsInFlight,
+		GroupsCreated,
+		RsvpsCreated,
+		RsvpsCancelled,
+		Payments,
+		TransactionRetries,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// RegisterPool exposes the statistics of the database pool.
+func RegisterPool(pool *pgxpool.Pool) {
+	registry.MustRegister(poolCollector{pool: pool})
+}
+
+// Instrument counts and times the requests the mux serves by the route pattern they matched. It must
+// wrap the mux itself, which only sets the pattern on the request it is given.
+func Instrument(mux http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestsInFlight.Inc()
+		defer requestsInFlight.Dec()
+
+		m := httpsnoop.CaptureMetrics(mux, w, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(m.Code)
+
+		requests.WithLabelValues(route, status).Inc()
+		requestDuration.WithLabelValues(route, status).Observe(m.Duration.Seconds())
+	}
+}
+
+var (
+	poolAcquiredConns = prometheus.NewDesc(namespace+"_db_pool_acquired_connections",
+		"Connections of the database pool in use.", nil, nil)
+	poolIdleConns = prometheus.NewDesc(namespace+"_db_pool_idle_connections",
+		"Idle connections of the database pool.", nil, nil)
+	poolTotalConns = prometheus.NewDesc(namespace+"_db_pool_connections",
+		"Connections of the database pool.", nil, nil)
+	poolMaxConns = prometheus.NewDesc(namespace+"_db_pool_max_connections",
+		"Maximum size of the database pool.", nil, nil)
+	poolAcquires = prometheus.NewDesc(namespace+"_db_pool_acquires_total",
+		"Connections acquired from the database pool.", nil, nil)
+	poolEmptyAcquires = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total",
+		"Acquires that had to wait for a connection because none was idle.", nil, nil)
+	poolCanceledAcquires = prometheus.NewDesc(namespace+"_db_pool_canceled_acquires_total",
+		"Acquires canceled before a connection was available.", nil, nil)
+	poolAcquireWait = prometheus.NewDesc(namespace+"_db_pool_acquire_wait_seconds_total",
+		"Time spent waiting for connections of the database pool.", nil, nil)
+)
+
+// poolCollector reads the statistics of a database pool when metrics are scraped.
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredConns
+	ch <- poolIdleConns
+	ch <- poolTotalConns
+	ch <- poolMaxConns
+	ch <- poolAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolCanceledAcquires
+	ch <- poolAcquireWait
+}
+
+func (c poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireWait, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ship-labs/meet-loop-api/internal"
+)
+
+// CodeAccountUpgradeRequired is returned to anonymous sessions for anything beyond browsing and RSVPing
+// to free public events. Clients should ask the user to sign up or link an identity.
+const CodeAccountUpgradeRequired = "account_upgrade_required"
+
+type allowAnonymousContextKey struct{}
+
+var allowAnonymousKey = allowAnonymousContextKey{}
+
+// AllowAnonymous lets anonymous Supabase sessions through the Auth or AuthOrAPIKey that next starts with.
+// Every other route requires a permanent account.
+func AllowAnonymous(next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		ctx := context.WithValue(r.Context(), allowAnonymousKey, true)
+		return next(w, r.WithContext(ctx))
+	}
+}
+
+// UpgradeRequired is the error for anonymous sessions attempting what takes a permanent account.
+func UpgradeRequired(reason string) error {
+	return &AuthError{
+		Code: CodeAccountUpgradeRequired,
+		Err:  fmt.Errorf("%w: %s", internal.ErrForbidden, reason),
+	}
+}
+
+// IsAnonymous reports whether the request is made in an anonymous session.
+func IsAnonymous(ctx context.Context) bool {
+	principal, err := GetPrincipal(ctx)
+	return err == nil && principal.IsAnonymous
+}
+
+func anonymousAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(allowAnonymousKey).(bool)
+	return allowed
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+)
+
+// APIKeyHeader carries the API key of partner systems that cannot sign users in.
+const APIKeyHeader = "X-API-Key"
+
+// Scopes an API key can be granted.
+const (
+	ScopeEventsRead  = "events:read"
+	ScopeMembersRead = "members:read"
+	ScopeRsvpsRead   = "rsvps:read"
+	ScopeRsvpsWrite  = "rsvps:write"
+)
+
+var Scopes = []string{ScopeEventsRead, ScopeMembersRead, ScopeRsvpsRead, ScopeRsvpsWrite}
+
+// Machine-readable codes of API key failures.
+const (
+	CodeAPIKeyInvalid       = "api_key_invalid"
+	CodeAPIKeyExpired       = "api_key_expired"
+	CodeAPIKeyScopeMissing  = "api_key_scope_missing"
+	CodeAPIKeyGroupMismatch = "api_key_group_mismatch"
+)
+
+type principalContextKey struct{}
+
+var principalKey = principalContextKey{}
+
+// Principal is whoever a request is made on behalf of: a signed in user, or a partner system using an
+// API key of a single group.
+type Principal struct {
+	// UserID is set for signed in users.
+	UserID pgtype.UUID
+	// IsAnonymous is set for users signed in with an anonymous Supabase session, who have yet to link a
+	// permanent identity.
+	IsAnonymous bool
+	// APIKey is set for requests authenticated with an API key.
+	APIKey *sqlc.ApiKey
+}
+
+func (p Principal) IsAPIKey() bool {
+	return p.APIKey != nil
+}
+
+// GetPrincipal returns who the request is made on behalf of.
+func GetPrincipal(ctx context.Context) (Principal, error) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	if !ok {
+		return Principal{}, fmt.Errorf("no principal found in context")
+	}
+	return principal, nil
+}
+
+// AuthOrAPIKey authenticates requests carrying an X-API-Key header with that key and every other request
+// like Auth. Keys must be granted scope and belong to the group in the {id} path parameter.
+func AuthOrAPIKey(store *sqlc.Store, scope string, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		key := r.Header.Get(APIKeyHeader)
+		if key == "" {
+			return Auth(next)
+		}
+
+		apiKey, err := authenticateAPIKey(r.Context(), store, key)
+		if err != nil {
+			return Error(err)
+		}
+
+		if !slices.Contains(apiKey.Scopes, scope) {
+			return Error(&AuthError{
+				Code: CodeAPIKeyScopeMissing,
+				Err:  fmt.Errorf("%w: API key is missing the %s scope", internal.ErrForbidden, scope),
+			})
+		}
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return Error(err)
+		}
+
+		if apiKey.GroupID != groupID {
+			return Error(&AuthError{
+				Code: CodeAPIKeyGroupMismatch,
+				Err:  fmt.Errorf("%w: API key belongs to another group", internal.ErrForbidden),
+			})
+		}
+
+		// Last used tracking must not fail the request.
+		if err := store.TouchApiKey(r.Context(), apiKey.ID); err != nil {
+			slog.ErrorContext(r.Context(), "apikeys", "message", "recording API key use", "apiKeyID", apiKey.ID, "error", err)
+		}
+
+		principal := Principal{APIKey: &apiKey}
+		ctx := context.WithValue(r.Context(), principalKey, principal)
+		recordPrincipal(ctx, principal)
+
+		return next(w, r.WithContext(ctx))
+	}
+}
+
+func authenticateAPIKey(ctx context.Context, store *sqlc.Store, key string) (sqlc.ApiKey, error) {
+	prefix, secret, ok := internal.ParseAPIKey(key)
+	if !ok {
+		return sqlc.ApiKey{}, unauthorized(CodeAPIKeyInvalid, nil)
+	}
+
+	apiKey, err := store.GetApiKeyByPrefix(ctx, prefix)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apiKey, unauthorized(CodeAPIKeyInvalid, nil)
+	}
+	if err != nil {
+		return apiKey, fmt.Errorf("getting API key: %w", err)
+	}
+
+	if !internal.APIKeySecretMatches(secret, apiKey.SecretHash) {
+		return apiKey, unauthorized(CodeAPIKeyInvalid, nil)
+	}
+
+	if apiKey.ExpiresAt.Valid && !apiKey.ExpiresAt.Time.After(time.Now().UTC()) {
+		return apiKey, unauthorized(CodeAPIKeyExpired, nil)
+	}
+
+	return apiKey, nil
+}
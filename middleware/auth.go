@@ -3,9 +3,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -25,6 +29,7 @@ type UserMetadata struct {
 	Email         string `json:"email"`
 	Name          string `json:"name"`
 	Phone         string `json:"phone"`
+	AvatarURL     string `json:"avatar_url"`
 	EmailVerified bool   `json:"email_verified"`
 	PhoneVerified bool   `json:"phone_verified"`
 	Sub           string `json:"sub"`
@@ -42,13 +47,9 @@ type AMR struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
-// JWTClaims represents the structure of the JWT payload
+// JWTClaims represents the structure of the JWT payload. The registered claims (iss, sub, aud, exp, nbf,
+// iat and jti) are read through the embedded jwt.RegisteredClaims so the parser validates them.
 type JWTClaims struct {
-	Iss          string       `json:"iss"`
-	Sub          string       `json:"sub"`
-	Aud          string       `json:"aud"`
-	Exp          int64        `json:"exp"`
-	Iat          int64        `json:"iat"`
 	Email        string       `json:"email"`
 	Phone        string       `json:"phone"`
 	AppMetadata  AppMetadata  `json:"app_metadata"`
@@ -61,52 +62,244 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// Machine-readable codes of authentication failures. Clients should refresh the session on
+// CodeTokenExpired and sign the user in again on any other code.
+const (
+	CodeTokenMissing          = "token_missing"
+	CodeTokenMalformed        = "token_malformed"
+	CodeTokenExpired          = "token_expired"
+	CodeTokenNotYetValid      = "token_not_yet_valid"
+	CodeTokenSignatureInvalid = "token_signature_invalid"
+	CodeTokenUnknownKey       = "token_unknown_key"
+	CodeTokenInvalidIssuer    = "token_invalid_issuer"
+	CodeTokenInvalidAudience  = "token_invalid_audience"
+	CodeTokenRoleNotAllowed   = "token_role_not_allowed"
+	CodeTokenInvalid          = "token_invalid"
+)
+
+// AuthError is an authentication failure along with the code clients use to tell whether refreshing the
+// token can help. It wraps internal.ErrUnauthorized, or internal.ErrForbidden for valid tokens whose role
+// is not allowed.
+type AuthError struct {
+	Code string
+	Err  error
+	// Data is sent along with the error when clients need more than the code to act on it.
+	Data any
+}
+
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+func unauthorized(code string, err error) error {
+	if err == nil {
+		return &AuthError{Code: code, Err: internal.ErrUnauthorized}
+	}
+	return &AuthError{Code: code, Err: fmt.Errorf("%w: %w", internal.ErrUnauthorized, err)}
+}
+
+// Policy is what a token must satisfy besides a valid signature. Empty fields are not checked.
+type Policy struct {
+	// Issuers are the accepted iss claims, one per auth server trusted during migrations.
+	Issuers  []string
+	Audience string
+	// Roles are the accepted role claims. Supabase signs anon and service_role keys as tokens too, and
+	// those must not pass as a signed in user.
+	Roles []string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+	// Denylist rejects the tokens of revoked sessions.
+	Denylist *Denylist
+}
+
+// Verifier checks the signature and validity of access tokens. HMAC tokens are verified with the shared
+// secret and asymmetric ones with the key their kid selects from the key set, so both can be accepted
+// side by side while the issuer migrates to asymmetric keys. Either may be left unset to reject the
+// tokens it would verify.
+type Verifier struct {
+	secret []byte
+	keys   *KeySet
+	policy Policy
+	parser *jwt.Parser
+}
+
+func NewVerifier(secret string, keys *KeySet, policy Policy) *Verifier {
+	options := []jwt.ParserOption{
+		jwt.WithLeeway(policy.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if policy.Audience != "" {
+		options = append(options, jwt.WithAudience(policy.Audience))
+	}
+
+	v := &Verifier{keys: keys, policy: policy, parser: jwt.NewParser(options...)}
+	if secret != "" {
+		v.secret = []byte(secret)
+	}
+	return v
+}
+
+var (
+	defaultVerifier     *Verifier
+	defaultVerifierErr  error
+	defaultVerifierOnce sync.Once
+)
+
+// Auth authenticates requests with the Verifier configured from the environment.
 func Auth(next Handler) Handler {
 	return func(w http.ResponseWriter, r *http.Request) Handler {
-		cfg, err := config.LoadConfig()
+		verifier, err := loadDefaultVerifier()
 		if err != nil {
 			return Error(err)
 		}
 
+		return verifier.Auth(next)(w, r)
+	}
+}
+
+// Verify checks a token with the Verifier configured from the environment, for tokens that arrive
+// other than in the Authorization header.
+func Verify(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	verifier, err := loadDefaultVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.Verify(ctx, tokenString)
+}
+
+func loadDefaultVerifier() (*Verifier, error) {
+	defaultVerifierOnce.Do(func() {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			defaultVerifierErr = err
+			return
+		}
+		keys := NewKeySet(cfg.JWKSEndpoint(), DefaultKeySetTTL, DefaultKeySetMinRefreshInterval)
+		defaultVerifier = NewVerifier(cfg.JwtSecret, keys, Policy{
+			Issuers:  cfg.JWTIssuerList(),
+			Audience: cfg.JWTAudience,
+			Roles:    cfg.JWTAllowedRoleList(),
+			Leeway:   cfg.JWTLeeway(),
+			Denylist: defaultDenylist,
+		})
+	})
+
+	return defaultVerifier, defaultVerifierErr
+}
+
+// Auth rejects requests without a valid bearer token, and anonymous sessions unless AllowAnonymous let
+// them in, and otherwise passes them to next with the token claims in the context.
+func (v *Verifier) Auth(next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
 		auth := r.Header.Get("Authorization")
 		prefix, tokenString, ok := strings.Cut(auth, " ")
 		if !ok {
-			return Error(internal.ErrUnauthorized)
+			return Error(unauthorized(CodeTokenMissing, nil))
 		}
 
 		if !strings.EqualFold(prefix, "Bearer") {
-			return Error(internal.ErrInvalidRequest)
+			return Error(unauthorized(CodeTokenMalformed, fmt.Errorf("unsupported authorization scheme %q", prefix)))
 		}
 
-		//   Parse token with custom claims struct
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (any, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(cfg.JwtSecret), nil
-		})
-
+		claims, err := v.Verify(r.Context(), tokenString)
 		if err != nil {
-			return Error(fmt.Errorf("parsing token: %w", err))
+			return Error(err)
 		}
 
-		if !token.Valid {
-			return Error(internal.ErrUnauthorized)
+		if claims.IsAnonymous && !anonymousAllowed(r.Context()) {
+			return Error(UpgradeRequired("anonymous sessions can only browse and RSVP to free public events"))
 		}
 
-		claims, ok := token.Claims.(*JWTClaims)
-		if !ok {
-			return Error(fmt.Errorf("invalid token claims"))
+		principal := Principal{IsAnonymous: claims.IsAnonymous}
+		if userID, err := uuid.Parse(claims.Subject); err == nil {
+			principal.UserID = pgtype.UUID{Bytes: userID, Valid: true}
 		}
 
 		// Update the request context with the claims
 		ctx := context.WithValue(r.Context(), jwtClaimsKey, claims)
+		ctx = context.WithValue(ctx, principalKey, principal)
+		recordPrincipal(ctx, principal)
 
 		// Call the next handler with the updated request
 		return next(w, r.WithContext(ctx))
 	}
 }
 
+// Verify parses tokenString and returns its claims when it is validly signed, within its validity window
+// and satisfies the policy. Failures are returned as an *AuthError.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	token, err := v.parser.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (any, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if v.secret == nil {
+				return nil, fmt.Errorf("HMAC signed tokens are not accepted")
+			}
+			return v.secret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+			if v.keys == nil {
+				return nil, fmt.Errorf("asymmetrically signed tokens are not accepted")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return v.keys.Key(ctx, kid, token.Method.Alg())
+		}
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	})
+	if err != nil {
+		return nil, unauthorized(tokenErrorCode(err), err)
+	}
+
+	if !token.Valid {
+		return nil, unauthorized(CodeTokenInvalid, nil)
+	}
+
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	if len(v.policy.Issuers) > 0 && !slices.Contains(v.policy.Issuers, claims.Issuer) {
+		return nil, unauthorized(CodeTokenInvalidIssuer, fmt.Errorf("issuer %q is not accepted", claims.Issuer))
+	}
+
+	if len(v.policy.Roles) > 0 && !slices.Contains(v.policy.Roles, claims.Role) {
+		return nil, &AuthError{
+			Code: CodeTokenRoleNotAllowed,
+			Err:  fmt.Errorf("%w: role %q is not allowed", internal.ErrForbidden, claims.Role),
+		}
+	}
+
+	if v.policy.Denylist != nil && v.policy.Denylist.Revoked(claims) {
+		return nil, unauthorized(CodeSessionRevoked, fmt.Errorf("session %q was revoked", claims.SessionID))
+	}
+
+	return claims, nil
+}
+
+// tokenErrorCode maps an error returned by the token parser to the code reported to clients.
+func tokenErrorCode(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return CodeTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return CodeTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return CodeTokenInvalidAudience
+	case errors.Is(err, ErrUnknownKey):
+		return CodeTokenUnknownKey
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return CodeTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenMalformed), errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return CodeTokenMalformed
+	}
+	return CodeTokenInvalid
+}
+
 // GetClaims returns the full JWT claims object from the context
 func GetClaims(ctx context.Context) (*JWTClaims, error) {
 	claims, ok := ctx.Value(jwtClaimsKey).(*JWTClaims)
@@ -124,9 +317,9 @@ func GetUserID(ctx context.Context) (pgtype.UUID, error) {
 		return pgtype.UUID{}, fmt.Errorf("getting userID: %w", err)
 	}
 
-	userID, err := uuid.Parse(claims.Sub)
+	userID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return pgtype.UUID{}, fmt.Errorf("parsing userID %s: %w", claims.Sub, err)
+		return pgtype.UUID{}, fmt.Errorf("parsing userID %s: %w", claims.Subject, err)
 	}
 
 	return pgtype.UUID{Bytes: userID, Valid: true}, nil
@@ -18,7 +18,8 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Vary", "Origin")
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, traceparent")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, traceparent")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		// Handle preflight requests
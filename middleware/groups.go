@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+)
+
+type membershipContextKey struct{}
+
+var membershipKey = membershipContextKey{}
+
+// Membership is the caller's standing in the group named by the {id} path parameter.
+type Membership struct {
+	Group sqlc.Group
+	// Member is only set when IsMember is true.
+	Member   sqlc.Member
+	IsMember bool
+	// IsAdmin is also true for admins of one of the group's parents, who need not be members, and for
+	// API keys of the group, which AuthOrAPIKey already limited to their scopes.
+	IsAdmin bool
+}
+
+// GroupMember runs next only for members and admins of the group in the path, with their Membership in
+// the request context. It answers 404 for groups that do not exist and 403 for everyone else.
+func GroupMember(store *sqlc.Store, next Handler) Handler {
+	return requireMembership(store, func(m Membership) bool { return m.IsMember || m.IsAdmin }, next)
+}
+
+// GroupAdmin runs next only for admins of the group in the path or of one of its parents, with their
+// Membership in the request context. It answers 404 for groups that do not exist and 403 for everyone else.
+func GroupAdmin(store *sqlc.Store, next Handler) Handler {
+	return requireMembership(store, func(m Membership) bool { return m.IsAdmin }, next)
+}
+
+// GetMembership returns the caller's Membership of groupID loaded by GroupMember or GroupAdmin, and false
+// when it was not loaded for that group.
+func GetMembership(ctx context.Context, groupID int64) (Membership, bool) {
+	membership, ok := ctx.Value(membershipKey).(Membership)
+	if !ok || membership.Group.ID != groupID {
+		return Membership{}, false
+	}
+	return membership, true
+}
+
+func requireMembership(store *sqlc.Store, allowed func(Membership) bool, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return Error(err)
+		}
+
+		membership, err := loadMembership(r.Context(), store, groupID)
+		if err != nil {
+			return Error(err)
+		}
+
+		if !allowed(membership) {
+			return Error(internal.ErrForbidden)
+		}
+
+		ctx := context.WithValue(r.Context(), membershipKey, membership)
+
+		return next(w, r.WithContext(ctx))
+	}
+}
+
+func loadMembership(ctx context.Context, store *sqlc.Store, groupID int64) (Membership, error) {
+	group, err := store.GetGroup(ctx, groupID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Membership{}, fmt.Errorf("group %w", internal.ErrNotExist)
+	}
+	if err != nil {
+		return Membership{}, fmt.Errorf("getting group %d: %w", groupID, err)
+	}
+
+	if principal, err := GetPrincipal(ctx); err == nil && principal.IsAPIKey() {
+		return Membership{Group: group, IsAdmin: principal.APIKey.GroupID == groupID}, nil
+	}
+
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		return Membership{}, fmt.Errorf("getting user ID: %w", err)
+	}
+
+	membership := Membership{Group: group}
+
+	member, err := store.GetGroupMember(ctx, sqlc.GetGroupMemberParams{
+		UserID:  userID,
+		GroupID: groupID,
+	})
+	switch {
+	case err == nil:
+		membership.Member = member
+		membership.IsMember = true
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Membership{}, fmt.Errorf("getting group member: %w", err)
+	}
+
+	membership.IsAdmin, err = store.IsUserGroupAdmin(ctx, sqlc.IsUserGroupAdminParams{
+		UserID:  userID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return Membership{}, fmt.Errorf("checking group admin: %w", err)
+	}
+
+	return membership, nil
+}
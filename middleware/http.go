@@ -7,18 +7,21 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/ship-labs/meet-loop-api/internal"
 )
 
 type Handler func(w http.ResponseWriter, r *http.Request) Handler
 
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if next := h(w, r); next != nil {
-		next.ServeHTTP(w, r)
+	for step := h; step != nil; {
+		step = serveStep(step, w, r)
 	}
 }
 
 type Response struct {
+	// Code is a machine-readable reason for the error, set when clients can act on it.
+	Code    string `json:"code,omitempty"`
 	Error   string `json:"error,omitempty"`
 	Data    any    `json:"data,omitempty"`
 	Message string `json:"message,omitempty"`
@@ -60,7 +63,16 @@ func CodeText(code int, text string) Handler {
 func Error(err error) Handler {
 	var code int
 	var v internal.ValidationError
+	var authErr *AuthError
+	var pgErr *pgconn.PgError
+	var policyViolation bool
 	var data any
+	var reason string
+
+	if errors.As(err, &authErr) {
+		reason = authErr.Code
+		data = authErr.Data
+	}
 
 	switch {
 	case errors.As(err, &v):
@@ -68,6 +80,14 @@ func Error(err error) Handler {
 		data = v.RawErrors()
 	case errors.Is(err, internal.ErrExists):
 		code = http.StatusConflict
+	case errors.Is(err, internal.ErrConflict):
+		code = http.StatusConflict
+	case errors.Is(err, internal.ErrArchived):
+		code = http.StatusConflict
+	case errors.As(err, &pgErr) && pgErr.Code == internal.ObjectNotInPrerequisiteStateCode:
+		// The group was archived after the handler checked it, and the database refused the write.
+		code = http.StatusConflict
+		err = internal.ErrArchived
 	case errors.Is(err, internal.ErrInvalidRequest):
 		code = http.StatusBadRequest
 	case errors.Is(err, internal.ErrUnmarshall):
@@ -80,6 +100,11 @@ func Error(err error) Handler {
 		code = http.StatusUnauthorized
 	case errors.Is(err, internal.ErrForbidden):
 		code = http.StatusForbidden
+	case errors.Is(err, internal.ErrRateLimited):
+		code = http.StatusTooManyRequests
+	case errors.As(err, &pgErr) && pgErr.Code == internal.InsufficientPrivilegeCode:
+		code = http.StatusForbidden
+		policyViolation = true
 	default:
 		code = http.StatusInternalServerError
 	}
@@ -90,12 +115,19 @@ func Error(err error) Handler {
 			err = internal.ErrInternal
 		}
 
+		// Row-level security caught what the handler let through, so the policy details are only logged.
+		if policyViolation {
+			slog.Log(r.Context(), slog.LevelWarn, "row-level security", "url", r.URL.Path, "error", err)
+			err = internal.ErrForbidden
+		}
+
 		if code == http.StatusBadRequest {
 			slog.Log(r.Context(), slog.LevelError, "bad request", "url", r.URL.Path, "error", err)
 			err = internal.ErrInvalidRequest
 		}
 
 		return Code(code, JSON(Response{
+			Code:    reason,
 			Error:   err.Error(),
 			Message: http.StatusText(code),
 			Data:    data,
@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultKeySetTTL is how long a fetched key set is used before it is fetched again.
+	DefaultKeySetTTL = 10 * time.Minute
+	// DefaultKeySetMinRefreshInterval bounds how often tokens signed with an unknown kid can trigger a fetch.
+	DefaultKeySetMinRefreshInterval = 30 * time.Second
+	keySetFetchTimeout              = 10 * time.Second
+)
+
+var ErrUnknownKey = errors.New("no signing key found for kid")
+
+// jwk is a single JSON Web Key as defined by RFC 7517. Only the fields of public signing keys are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type signingKey struct {
+	alg string
+	key any
+}
+
+// KeySet fetches the JSON Web Key Set published at a URL and caches its keys by kid. The set is fetched
+// again once it is older than its TTL, or when a token references a kid it does not contain, at most
+// once per minimum refresh interval so forged kids cannot be used to flood the issuer.
+type KeySet struct {
+	url                string
+	client             *http.Client
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]signingKey
+	fetchedAt   time.Time
+	refreshedAt time.Time
+
+	// refresh serializes fetches so concurrent requests with the same unknown kid fetch once.
+	refresh sync.Mutex
+}
+
+// NewKeySet returns a KeySet for the JWKS published at url. Keys are fetched lazily on first use.
+func NewKeySet(url string, ttl, minRefreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		url:                url,
+		client:             &http.Client{Timeout: keySetFetchTimeout},
+		ttl:                ttl,
+		minRefreshInterval: minRefreshInterval,
+	}
+}
+
+// Key returns the public key for kid, checking that it may be used with alg.
+func (k *KeySet) Key(ctx context.Context, kid, alg string) (any, error) {
+	key, ok, fresh := k.lookup(kid)
+	if !ok || !fresh {
+		if err := k.Refresh(ctx, !ok); err != nil {
+			// A stale key is still better than none when the issuer cannot be reached.
+			if !ok {
+				return nil, err
+			}
+		}
+		key, ok, _ = k.lookup(kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("key %q is for %s, not %s", kid, key.alg, alg)
+	}
+
+	return key.key, nil
+}
+
+// Refresh fetches the key set again. Unless force is set, nothing is fetched when the cached set is still
+// fresh. A fetch is never issued more often than the minimum refresh interval.
+func (k *KeySet) Refresh(ctx context.Context, force bool) error {
+	k.refresh.Lock()
+	defer k.refresh.Unlock()
+
+	k.mu.RLock()
+	fresh := time.Since(k.fetchedAt) < k.ttl
+	throttled := time.Since(k.refreshedAt) < k.minRefreshInterval
+	k.mu.RUnlock()
+
+	if (fresh && !force) || throttled {
+		return nil
+	}
+
+	k.mu.Lock()
+	k.refreshedAt = time.Now()
+	k.mu.Unlock()
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+
+	return nil
+}
+
+func (k *KeySet) lookup(kid string) (key signingKey, ok, fresh bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok = k.keys[kid]
+	return key, ok, time.Since(k.fetchedAt) < k.ttl
+}
+
+func (k *KeySet) fetch(ctx context.Context) (map[string]signingKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating JWKS request: %w", err)
+	}
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: unexpected status %s", res.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]signingKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		public, err := key.publicKey()
+		if err != nil {
+			// One malformed or unsupported key must not take down the others.
+			continue
+		}
+
+		keys[key.Kid] = signingKey{alg: key.Alg, key: public}
+	}
+
+	return keys, nil
+}
+
+func (j jwk) publicKey() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeInt(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding modulus: %w", err)
+		}
+		e, err := decodeInt(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeInt(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+		y, err := decodeInt(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", j.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
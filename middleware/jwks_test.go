@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret-of-at-least-thirty-two-bytes"
+
+// jwksServer publishes a key set from an in-process HTTP server and counts how often it is fetched.
+type jwksServer struct {
+	*httptest.Server
+	fetches atomic.Int64
+
+	mu   sync.Mutex
+	keys []jwk
+}
+
+func newJWKSServer(t *testing.T, keys ...jwk) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string][]jwk{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// publish replaces the keys the server publishes, as an issuer rotating its keys does.
+func (s *jwksServer) publish(keys ...jwk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func rsaKey(t *testing.T, kid string) (*rsa.PrivateKey, jwk) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+
+	return key, jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecKey(t *testing.T, kid string) (*ecdsa.PrivateKey, jwk) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating EC key: %v", err)
+	}
+
+	return key, jwk{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Alg: "ES256",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any) string {
+	t.Helper()
+
+	now := time.Now()
+	token := jwt.NewWithClaims(method, JWTClaims{
+		Role: "authenticated",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "8d1f0b4e-6c1a-4a55-9d0e-6f7f2b1c3a10",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+func authErrorCode(err error) string {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return authErr.Code
+	}
+	return ""
+}
+
+func TestKeySetSelectsKeyByKid(t *testing.T) {
+	rsaPrivate, rsaJWK := rsaKey(t, "rsa")
+	ecPrivate, ecJWK := ecKey(t, "ec")
+	server := newJWKSServer(t, rsaJWK, ecJWK)
+	keys := NewKeySet(server.URL, time.Hour, 0)
+
+	key, err := keys.Key(context.Background(), "rsa", "RS256")
+	if err != nil {
+		t.Fatalf("getting RSA key: %v", err)
+	}
+	if public, ok := key.(*rsa.PublicKey); !ok || !public.Equal(&rsaPrivate.PublicKey) {
+		t.Errorf("got key %T for kid rsa, want the published RSA key", key)
+	}
+
+	key, err = keys.Key(context.Background(), "ec", "ES256")
+	if err != nil {
+		t.Fatalf("getting EC key: %v", err)
+	}
+	if public, ok := key.(*ecdsa.PublicKey); !ok || !public.Equal(&ecPrivate.PublicKey) {
+		t.Errorf("got key %T for kid ec, want the published EC key", key)
+	}
+
+	if _, err := keys.Key(context.Background(), "rsa", "ES256"); err == nil {
+		t.Error("got RSA key for ES256, want an error")
+	}
+
+	if fetches := server.fetches.Load(); fetches != 1 {
+		t.Errorf("fetched the key set %d times, want 1", fetches)
+	}
+}
+
+func TestKeySetRefreshesOnUnknownKid(t *testing.T) {
+	_, oldJWK := rsaKey(t, "old")
+	_, newJWK := ecKey(t, "new")
+	server := newJWKSServer(t, oldJWK)
+	keys := NewKeySet(server.URL, time.Hour, 0)
+
+	if _, err := keys.Key(context.Background(), "old", "RS256"); err != nil {
+		t.Fatalf("getting key old: %v", err)
+	}
+
+	server.publish(oldJWK, newJWK)
+
+	if _, err := keys.Key(context.Background(), "new", "ES256"); err != nil {
+		t.Fatalf("getting key new after it was published: %v", err)
+	}
+	if fetches := server.fetches.Load(); fetches != 2 {
+		t.Errorf("fetched the key set %d times, want 2", fetches)
+	}
+}
+
+func TestKeySetThrottlesRefresh(t *testing.T) {
+	_, key := rsaKey(t, "known")
+	server := newJWKSServer(t, key)
+	keys := NewKeySet(server.URL, time.Hour, time.Hour)
+
+	if _, err := keys.Key(context.Background(), "known", "RS256"); err != nil {
+		t.Fatalf("getting key known: %v", err)
+	}
+
+	for range 5 {
+		if _, err := keys.Key(context.Background(), "forged", "RS256"); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("got error %v for kid forged, want ErrUnknownKey", err)
+		}
+	}
+
+	if fetches := server.fetches.Load(); fetches != 1 {
+		t.Errorf("fetched the key set %d times, want 1 within the minimum refresh interval", fetches)
+	}
+}
+
+func TestVerifierAcceptsAsymmetricAndHMACTokens(t *testing.T) {
+	rsaPrivate, rsaJWK := rsaKey(t, "rsa")
+	ecPrivate, ecJWK := ecKey(t, "ec")
+	server := newJWKSServer(t, rsaJWK, ecJWK)
+	verifier := NewVerifier(testSecret, NewKeySet(server.URL, time.Hour, 0), Policy{Roles: []string{"authenticated"}})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"RS256", sign(t, jwt.SigningMethodRS256, "rsa", rsaPrivate)},
+		{"ES256", sign(t, jwt.SigningMethodES256, "ec", ecPrivate)},
+		{"HS256", sign(t, jwt.SigningMethodHS256, "", []byte(testSecret))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("verifying token: %v", err)
+			}
+			if claims.Subject == "" {
+				t.Error("got no subject")
+			}
+		})
+	}
+
+	t.Run("HS256 with another secret", func(t *testing.T) {
+		token := sign(t, jwt.SigningMethodHS256, "", []byte("another-secret-of-at-least-thirty-two-bytes"))
+		_, err := verifier.Verify(context.Background(), token)
+		if code := authErrorCode(err); code != CodeTokenSignatureInvalid {
+			t.Errorf("got code %q, want %q", code, CodeTokenSignatureInvalid)
+		}
+	})
+
+	t.Run("RS256 with a kid of another key", func(t *testing.T) {
+		token := sign(t, jwt.SigningMethodRS256, "ec", rsaPrivate)
+		if _, err := verifier.Verify(context.Background(), token); err == nil {
+			t.Error("verified token signed with another key than its kid selects")
+		}
+	})
+}
+
+func TestVerifierRejectsRotatedOutKey(t *testing.T) {
+	oldPrivate, oldJWK := rsaKey(t, "old")
+	newPrivate, newJWK := rsaKey(t, "new")
+	server := newJWKSServer(t, oldJWK)
+	// Without a TTL the key set is fetched again on every lookup, as it is once the TTL passes.
+	verifier := NewVerifier("", NewKeySet(server.URL, 0, 0), Policy{})
+
+	oldToken := sign(t, jwt.SigningMethodRS256, "old", oldPrivate)
+	if _, err := verifier.Verify(context.Background(), oldToken); err != nil {
+		t.Fatalf("verifying token of the published key: %v", err)
+	}
+
+	server.publish(newJWK)
+
+	_, err := verifier.Verify(context.Background(), oldToken)
+	if code := authErrorCode(err); code != CodeTokenUnknownKey {
+		t.Errorf("got code %q for the rotated out key, want %q", code, CodeTokenUnknownKey)
+	}
+
+	if _, err := verifier.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "new", newPrivate)); err != nil {
+		t.Errorf("verifying token of the rotated in key: %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+)
+
+// memoryRateLimitSweepInterval is how often the in-memory store drops the buckets that refilled.
+const memoryRateLimitSweepInterval = time.Minute
+
+// Limit is a token bucket holding Requests tokens that refills evenly over Per, so clients can make
+// Requests requests at once and then one every Per/Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// rate returns how many tokens the bucket refills a second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateLimitStore keeps the token buckets of a RateLimiter.
+type RateLimitStore interface {
+	// Take refills the bucket of key and takes a token from it, returning the tokens left. A negative
+	// result means the bucket was empty and the request is denied; the bucket then holds one token more.
+	Take(ctx context.Context, key string, limit Limit) (float64, error)
+}
+
+// RateLimiter limits how often each user, API key or, for other requests, client IP can call an endpoint.
+type RateLimiter struct {
+	store          RateLimitStore
+	trustedProxies []netip.Prefix
+}
+
+// NewRateLimiter returns a RateLimiter keeping its buckets in store. Client IPs are read from
+// X-Forwarded-For as far as the request passed through trustedProxies.
+func NewRateLimiter(store RateLimitStore, trustedProxies []netip.Prefix) *RateLimiter {
+	return &RateLimiter{store: store, trustedProxies: trustedProxies}
+}
+
+// Limit rejects requests with 429 Too Many Requests once their client has used up limit on the route,
+// and reports the state of the bucket in RateLimit-* headers. It must run after Auth or AuthOrAPIKey to
+// tell users and API keys apart.
+func (l *RateLimiter) Limit(limit Limit, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		return l.take(w, r, r.Pattern+" "+l.client(r), limit, next)
+	}
+}
+
+// LimitIP is like Limit but counts every request against its client IP. It runs before Auth, so requests
+// are limited before their token is verified, whether it turns out to be valid or not.
+func (l *RateLimiter) LimitIP(limit Limit, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		return l.take(w, r, r.Pattern+" ip:"+ClientIP(r, l.trustedProxies).String(), limit, next)
+	}
+}
+
+// take takes a token from the bucket of key, calling next when there was one.
+func (l *RateLimiter) take(w http.ResponseWriter, r *http.Request, key string, limit Limit, next Handler) Handler {
+	tokens, err := l.store.Take(r.Context(), key, limit)
+	if err != nil {
+		// An unavailable store must not take the endpoints it protects down with it.
+		slog.ErrorContext(r.Context(), "rate limit", "message", "taking token", "key", key, "error", err)
+		return next(w, r)
+	}
+
+	held := tokens
+	if tokens < 0 {
+		held = tokens + 1
+	}
+	rate := limit.rate()
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	header.Set("RateLimit-Remaining", strconv.Itoa(int(max(tokens, 0))))
+	header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(limit.Requests)-held)/rate))))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Per.Seconds())))
+
+	if tokens < 0 {
+		retryAfter := int(math.Ceil((1 - held) / rate))
+		header.Set("Retry-After", strconv.Itoa(retryAfter))
+		return Error(fmt.Errorf("%w: retry in %d seconds", internal.ErrRateLimited, retryAfter))
+	}
+
+	return next(w, r)
+}
+
+// client identifies who a request counts against.
+func (l *RateLimiter) client(r *http.Request) string {
+	if principal, err := GetPrincipal(r.Context()); err == nil {
+		switch {
+		case principal.IsAPIKey():
+			return fmt.Sprintf("key:%d", principal.APIKey.ID)
+		case principal.UserID.Valid:
+			return "user:" + uuid.UUID(principal.UserID.Bytes).String()
+		}
+	}
+	return "ip:" + ClientIP(r, l.trustedProxies).String()
+}
+
+// ClientIP returns the address of the client that made r. X-Forwarded-For is only believed as far as the
+// request passed through trustedProxies: its addresses are read from the right, where each proxy appends
+// the one it was called from, up to the first untrusted one, as clients can put anything to its left.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	var ip netip.Addr
+	if addrPort, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		ip = addrPort.Addr().Unmap()
+	}
+
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0 && trusted(ip, trustedProxies); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = hop.Unmap()
+	}
+
+	return ip
+}
+
+func trusted(ip netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryRateLimitStore keeps token buckets in memory. Each instance then limits clients on its own, so
+// it suits single instance deployments and development.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	sweptAt time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	per       time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*memoryBucket{}, sweptAt: time.Now()}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit Limit) (float64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.sweptAt) >= memoryRateLimitSweepInterval {
+		// Buckets that had time to refill are no different from missing ones.
+		for key, bucket := range s.buckets {
+			if now.Sub(bucket.updatedAt) >= bucket.per {
+				delete(s.buckets, key)
+			}
+		}
+		s.sweptAt = now
+	}
+
+	tokens := float64(limit.Requests)
+	bucket, ok := s.buckets[key]
+	if ok {
+		tokens = min(tokens, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*limit.rate())
+	} else {
+		bucket = &memoryBucket{}
+		s.buckets[key] = bucket
+	}
+
+	bucket.updatedAt = now
+	bucket.per = limit.Per
+	if tokens < 1 {
+		bucket.tokens = tokens
+		return tokens - 1, nil
+	}
+
+	bucket.tokens = tokens - 1
+	return bucket.tokens, nil
+}
+
+// PostgresRateLimitStore keeps token buckets in the database, so every instance of a deployment that
+// scales out, as on Cloud Run, enforces the same limits.
+type PostgresRateLimitStore struct {
+	store *sqlc.Store
+
+	mu sync.Mutex
+	// longest is the longest period of the limits taken from, after which any bucket has refilled.
+	longest time.Duration
+}
+
+func NewPostgresRateLimitStore(store *sqlc.Store) *PostgresRateLimitStore {
+	return &PostgresRateLimitStore{store: store}
+}
+
+func (s *PostgresRateLimitStore) Take(ctx context.Context, key string, limit Limit) (float64, error) {
+	s.mu.Lock()
+	s.longest = max(s.longest, limit.Per)
+	s.mu.Unlock()
+
+	// Tokens are spent even when the request transaction is rolled back.
+	tokens, err := s.store.TakeRateLimitToken(sqlc.WithoutTx(ctx), sqlc.TakeRateLimitTokenParams{
+		Key:      key,
+		Capacity: float64(limit.Requests),
+		Rate:     limit.rate(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("taking rate limit token: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Run deletes the buckets that had time to refill every interval until ctx is done.
+func (s *PostgresRateLimitStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		longest := s.longest
+		s.mu.Unlock()
+		if longest == 0 {
+			continue
+		}
+
+		pruned, err := s.store.PruneRateLimitBuckets(ctx, pgtype.Timestamp{Time: time.Now().UTC().Add(-longest), Valid: true})
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "rate limit", "message", "pruning buckets", "error", err)
+		}
+		if pruned > 0 {
+			slog.InfoContext(ctx, "rate limit", "message", "pruned buckets", "count", pruned)
+		}
+	}
+}
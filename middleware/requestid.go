@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader carries the ID a request is logged under, chosen by the client or generated.
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader carries the W3C trace context of a request.
+	TraceparentHeader  = "traceparent"
+	maxRequestIDLength = 128
+)
+
+type requestInfoContextKey struct{}
+
+var requestInfoKey = requestInfoContextKey{}
+
+// RequestInfo identifies a request in logs and across services.
+type RequestInfo struct {
+	RequestID string
+	// TraceID and SpanID are the W3C trace context of the request, continuing the trace of the caller
+	// when it sent a traceparent. SpanID is the span of this server and ParentID that of the caller.
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Sampled  bool
+	// userID and apiKeyID are recorded on authentication so the request line logged on the way out
+	// carries them too.
+	userID   string
+	apiKeyID int64
+}
+
+// Traceparent returns the traceparent header value of the request's span.
+func (i *RequestInfo) Traceparent() string {
+	flags := "00"
+	if i.Sampled {
+		flags = "01"
+	}
+	return "00-" + i.TraceID + "-" + i.SpanID + "-" + flags
+}
+
+// RequestContext accepts the X-Request-ID and traceparent of incoming requests, or generates them, stores
+// them in the request context and returns them in the response, so the logs of a request can be told
+// apart and correlated with those of the services it passed through. It must run inside the
+// OpenTelemetry handler for logs to match the spans exported.
+func RequestContext(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := &RequestInfo{RequestID: r.Header.Get(RequestIDHeader)}
+		if !validRequestID(info.RequestID) {
+			info.RequestID = uuid.NewString()
+		}
+
+		traceID, parentID, sampled, ok := parseTraceparent(r.Header.Get(TraceparentHeader))
+		if ok {
+			info.TraceID, info.ParentID, info.Sampled = traceID, parentID, sampled
+		} else {
+			info.TraceID = randomHex(16)
+		}
+		info.SpanID = randomHex(8)
+
+		// Requests traced with OpenTelemetry are logged under the IDs of their server span. Without a
+		// tracer provider the context only holds the remote span of the caller.
+		if span := trace.SpanContextFromContext(r.Context()); span.IsValid() && !span.IsRemote() {
+			info.TraceID, info.SpanID, info.Sampled = span.TraceID().String(), span.SpanID().String(), span.IsSampled()
+		}
+
+		w.Header().Set(RequestIDHeader, info.RequestID)
+		w.Header().Set(TraceparentHeader, info.Traceparent())
+
+		ctx := context.WithValue(r.Context(), requestInfoKey, info)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// GetRequestInfo returns the IDs of the request ctx belongs to, or nil outside of requests.
+func GetRequestInfo(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey).(*RequestInfo)
+	return info
+}
+
+// recordPrincipal attaches the user or API key a request is made on behalf of to its logs.
+func recordPrincipal(ctx context.Context, principal Principal) {
+	info := GetRequestInfo(ctx)
+	if info == nil {
+		return
+	}
+
+	switch {
+	case principal.IsAPIKey():
+		info.apiKeyID = principal.APIKey.ID
+	case principal.UserID.Valid:
+		info.userID = uuid.UUID(principal.UserID.Bytes).String()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceparent parses a traceparent header. Invalid headers, and those with all zero IDs, are ignored
+// and start a new trace, as the W3C Trace Context specification requires. Later versions may append
+// fields, which are skipped.
+func parseTraceparent(header string) (traceID, parentID string, sampled, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || parts[0] == "00" && len(parts) != 4 {
+		return "", "", false, false
+	}
+
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if version == "ff" || !lowerHex(version, 2) || !lowerHex(traceID, 32) || !lowerHex(parentID, 16) || !lowerHex(flags, 2) {
+		return "", "", false, false
+	}
+
+	if strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return "", "", false, false
+	}
+
+	flagBits, _ := hex.DecodeString(flags)
+	return traceID, parentID, flagBits[0]&1 == 1, true
+}
+
+func lowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextHandler is a slog.Handler that adds the request and trace IDs of the request a context belongs
+// to, and the user or API key it is made on behalf of, to every record logged with it.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info := GetRequestInfo(ctx); info != nil {
+		record.AddAttrs(
+			slog.String("requestID", info.RequestID),
+			slog.String("traceID", info.TraceID),
+			slog.String("spanID", info.SpanID),
+		)
+		if info.userID != "" {
+			record.AddAttrs(slog.String("userID", info.userID))
+		}
+		if info.apiKeyID != 0 {
+			record.AddAttrs(slog.Int64("apiKeyID", info.apiKeyID))
+		}
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
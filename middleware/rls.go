@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+)
+
+// RowLevelSecurity runs every query next issues for a signed in user in one transaction that assumes the
+// role of their token and exposes its claims as request.jwt.claims, so the database's row-level security
+// policies and auth.uid() apply as they would to the user's own Supabase client. The transaction is
+// committed only when the response is successful, and the response is held back until it is.
+//
+// Requests authenticated with an API key pass through unchanged, as AuthOrAPIKey already confines them
+// to their group and scopes. It must run after Auth or AuthOrAPIKey.
+func RowLevelSecurity(store *sqlc.Store, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		claims, err := GetClaims(r.Context())
+		if err != nil {
+			return next(w, r)
+		}
+
+		if claims.Role == "" {
+			return Error(unauthorized(CodeTokenInvalid, fmt.Errorf("token has no role")))
+		}
+
+		payload, err := json.Marshal(claims)
+		if err != nil {
+			return Error(fmt.Errorf("encoding claims: %w", err))
+		}
+
+		ctx, tx, err := store.BeginContext(r.Context())
+		if err != nil {
+			return Error(err)
+		}
+		// Rolling back after a commit is a no-op, so this only ends transactions that failed or panicked.
+		defer tx.Rollback(ctx)
+
+		err = store.SetRequestClaims(ctx, sqlc.SetRequestClaimsParams{
+			Role:   claims.Role,
+			Claims: string(payload),
+		})
+		if err != nil {
+			return Error(fmt.Errorf("setting request claims: %w", err))
+		}
+
+		r = r.WithContext(ctx)
+		res := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		if h := next(w, r); h != nil {
+			h.ServeHTTP(res, r)
+		}
+
+		if res.status < http.StatusBadRequest {
+			if err := tx.Commit(ctx); err != nil {
+				return Error(fmt.Errorf("committing request transaction: %w", err))
+			}
+		}
+
+		return res.flush
+	}
+}
+
+// bufferedResponse holds a response back until the request transaction has been committed, so clients
+// never see a success that was rolled back.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter, r *http.Request) Handler {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+	return OK
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+)
+
+// CodeSessionRevoked is reported for tokens of a session that was revoked. Refreshing cannot help, so
+// clients should sign the user in again.
+const CodeSessionRevoked = "session_revoked"
+
+const (
+	// SessionRevocationChannel is the channel the database notifies of new session revocations.
+	SessionRevocationChannel = "session_revocations"
+	// DefaultDenylistRefreshInterval is how often the denylist is reloaded in case a notification was missed.
+	DefaultDenylistRefreshInterval = time.Minute
+	denylistReconnectDelay         = 5 * time.Second
+)
+
+// Denylist rejects the access tokens of revoked sessions until they expire. Revocations are stored in
+// the database and cached in memory, and the cache is reloaded whenever the database notifies that a
+// session was revoked, so revocations made through any instance apply to all of them within seconds.
+type Denylist struct {
+	store     *sqlc.Store
+	retention time.Duration
+
+	mu sync.RWMutex
+	// sessions and users map revoked session and user IDs to when they were last revoked.
+	sessions map[uuid.UUID]time.Time
+	users    map[uuid.UUID]time.Time
+}
+
+// NewDenylist returns a Denylist that keeps revocations for retention, which must be at least as long as
+// access tokens can be valid for. It is empty until it is loaded.
+func NewDenylist(store *sqlc.Store, retention time.Duration) *Denylist {
+	return &Denylist{
+		store:     store,
+		retention: retention,
+		sessions:  map[uuid.UUID]time.Time{},
+		users:     map[uuid.UUID]time.Time{},
+	}
+}
+
+// Revoked reports whether the token with claims belongs to a revoked session, or was issued to a user
+// before all of their sessions were revoked.
+func (d *Denylist) Revoked(claims *JWTClaims) bool {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if _, ok := d.sessions[sessionID]; ok {
+			return true
+		}
+	}
+
+	if userID, err := uuid.Parse(claims.Subject); err == nil {
+		if revokedAt, ok := d.users[userID]; ok && !issuedAt.After(revokedAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Load replaces the cached revocations with those made within the retention period.
+func (d *Denylist) Load(ctx context.Context) error {
+	revocations, err := d.store.ListSessionRevocations(ctx, pgtype.Timestamp{Time: time.Now().UTC().Add(-d.retention), Valid: true})
+	if err != nil {
+		return fmt.Errorf("listing session revocations: %w", err)
+	}
+
+	sessions := map[uuid.UUID]time.Time{}
+	users := map[uuid.UUID]time.Time{}
+	for _, revocation := range revocations {
+		if revocation.SessionID.Valid {
+			sessions[revocation.SessionID.Bytes] = revocation.RevokedAt.Time
+		} else {
+			users[revocation.UserID.Bytes] = revocation.RevokedAt.Time
+		}
+	}
+
+	d.mu.Lock()
+	d.sessions, d.users = sessions, users
+	d.mu.Unlock()
+
+	return nil
+}
+
+// Run keeps the denylist up to date until ctx is done. It reloads it whenever the database notifies of a
+// revocation, and every interval in case notifications were missed, pruning revocations that outlived
+// the tokens they reject.
+func (d *Denylist) Run(ctx context.Context, interval time.Duration) {
+	notified := make(chan struct{}, 1)
+	notify := func() {
+		// A reload that is already pending picks up this revocation as well.
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	}
+	go d.listen(ctx, notify)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Load(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "sessions", "message", "loading session revocations", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-notified:
+		case <-ticker.C:
+			pruned, err := d.store.PruneSessionRevocations(ctx, pgtype.Timestamp{Time: time.Now().UTC().Add(-d.retention), Valid: true})
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "sessions", "message", "pruning session revocations", "error", err)
+			}
+			if pruned > 0 {
+				slog.InfoContext(ctx, "sessions", "message", "pruned session revocations", "count", pruned)
+			}
+		}
+	}
+}
+
+// listen calls notify on every revocation notification, listening again whenever the connection fails.
+// Notifications sent while it reconnects are lost, so it also calls notify before listening again.
+func (d *Denylist) listen(ctx context.Context, notify func()) {
+	for {
+		err := d.store.Listen(ctx, SessionRevocationChannel, func(string) { notify() })
+		if ctx.Err() != nil {
+			return
+		}
+		slog.ErrorContext(ctx, "sessions", "message", "listening for session revocations", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(denylistReconnectDelay):
+		}
+		notify()
+	}
+}
+
+var defaultDenylist *Denylist
+
+// UseDenylist makes Auth and Verify reject the tokens that d revoked. It must be called before the first
+// request is authenticated.
+func UseDenylist(d *Denylist) {
+	defaultDenylist = d
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ship-labs/meet-loop-api/internal"
+)
+
+// CodeStepUpRequired is returned for sensitive operations when the session lacks multi-factor
+// authentication or the user last authenticated too long ago. Clients should run an MFA challenge, which
+// raises the session to aal2 and records a fresh authentication, and retry with the new token.
+const CodeStepUpRequired = "step_up_required"
+
+// AAL2 is the assurance level of sessions verified with a second factor.
+const AAL2 = "aal2"
+
+// StepUp tells clients what a sensitive operation requires of the session.
+type StepUp struct {
+	RequiredAAL string `json:"required_aal"`
+	CurrentAAL  string `json:"current_aal"`
+	// MaxAgeSeconds is how recently the user must have authenticated.
+	MaxAgeSeconds   int64      `json:"max_age_seconds"`
+	AuthenticatedAt *time.Time `json:"authenticated_at,omitempty"`
+}
+
+// RequireStepUp runs next only for sessions at aal2 whose latest authentication method, per the amr claim,
+// was used within maxAge. It must run after Auth.
+func RequireStepUp(maxAge time.Duration, next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) Handler {
+		claims, err := GetClaims(r.Context())
+		if err != nil {
+			return Error(unauthorized(CodeTokenMissing, err))
+		}
+
+		stepUp := StepUp{
+			RequiredAAL:   AAL2,
+			CurrentAAL:    claims.AAL,
+			MaxAgeSeconds: int64(maxAge / time.Second),
+		}
+
+		var authenticatedAt time.Time
+		for _, amr := range claims.AMR {
+			if t := time.Unix(amr.Timestamp, 0); t.After(authenticatedAt) {
+				authenticatedAt = t
+			}
+		}
+		if !authenticatedAt.IsZero() {
+			stepUp.AuthenticatedAt = &authenticatedAt
+		}
+
+		var reason string
+		switch {
+		case claims.AAL != AAL2:
+			reason = "this operation requires multi-factor authentication"
+		case authenticatedAt.IsZero() || time.Since(authenticatedAt) > maxAge:
+			reason = "this operation requires a recent authentication"
+		default:
+			return next(w, r)
+		}
+
+		return Error(&AuthError{
+			Code: CodeStepUpRequired,
+			Err:  fmt.Errorf("%w: %s", internal.ErrForbidden, reason),
+			Data: stepUp,
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ship-labs/meet-loop-api/middleware")
+
+// TraceRoutes names the span of each request after the route pattern it matched, and records the pattern
+// as its http.route. The ServeMux only sets the pattern on the request it is given, which the handlers
+// that started the span never see.
+func TraceRoutes(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+
+		if r.Pattern != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Pattern)
+			span.SetAttributes(attribute.String("http.route", r.Pattern))
+		}
+	}
+}
+
+// serveStep runs one step of a Handler chain, in a span of its own when the request is traced.
+func serveStep(step Handler, w http.ResponseWriter, r *http.Request) Handler {
+	if !trace.SpanFromContext(r.Context()).IsRecording() {
+		return step(w, r)
+	}
+
+	ctx, span := tracer.Start(r.Context(), stepName(step))
+	defer span.End()
+
+	return step(w, r.WithContext(ctx))
+}
+
+// stepName returns the name of the function a step is made of, such as middleware.Auth.func1.
+func stepName(step Handler) string {
+	f := runtime.FuncForPC(reflect.ValueOf(step).Pointer())
+	if f == nil {
+		return "handler"
+	}
+	name := f.Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,116 @@
+// Package notifications delivers messages to members over pluggable channels such as email, SMS and in-app.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type Channel string
+
+const (
+	Email Channel = "email"
+	SMS   Channel = "sms"
+	InApp Channel = "in_app"
+	Push  Channel = "push"
+)
+
+var (
+	ErrUnsupportedChannel = errors.New("unsupported notification channel")
+	ErrNoAddress          = errors.New("recipient has no address for channel")
+	ErrNoProvider         = errors.New("no provider configured")
+)
+
+// Recipient identifies the member a message is delivered to along with their contact details.
+type Recipient struct {
+	MemberID int64
+	Name     string
+	Email    string
+	Phone    string
+}
+
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Sender delivers a message to a single recipient over one channel.
+type Sender interface {
+	Send(ctx context.Context, recipient Recipient, message Message) error
+}
+
+// SenderFunc adapts an ordinary function to the Sender interface.
+type SenderFunc func(ctx context.Context, recipient Recipient, message Message) error
+
+func (f SenderFunc) Send(ctx context.Context, recipient Recipient, message Message) error {
+	return f(ctx, recipient, message)
+}
+
+// Dispatcher routes messages to the Sender registered for each channel.
+type Dispatcher struct {
+	senders map[Channel]Sender
+}
+
+// NewDispatcher returns a Dispatcher with every channel registered. In-app messages are read straight
+// from the database so their sender is a no-op, while email, SMS and push fail with ErrNoProvider until a
+// provider is registered with Register.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		senders: map[Channel]Sender{
+			InApp: SenderFunc(func(context.Context, Recipient, Message) error { return nil }),
+			Email: unconfigured,
+			SMS:   unconfigured,
+			Push:  unconfigured,
+		},
+	}
+}
+
+// Register replaces the sender used for a channel.
+func (d *Dispatcher) Register(channel Channel, sender Sender) {
+	d.senders[channel] = sender
+}
+
+// Channels returns the registered channels in a stable order.
+func (d *Dispatcher) Channels() []Channel {
+	channels := make([]Channel, 0, len(d.senders))
+	for channel := range d.senders {
+		channels = append(channels, channel)
+	}
+	slices.Sort(channels)
+	return channels
+}
+
+func (d *Dispatcher) Supports(channel Channel) bool {
+	_, ok := d.senders[channel]
+	return ok
+}
+
+// Send delivers message to recipient over channel. It returns ErrNoAddress when the recipient cannot be
+// reached on that channel and ErrNoProvider when nothing can deliver over it yet, which callers should
+// treat as skipped rather than failed.
+func (d *Dispatcher) Send(ctx context.Context, channel Channel, recipient Recipient, message Message) error {
+	sender, ok := d.senders[channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedChannel, channel)
+	}
+
+	switch {
+	case channel == Email && recipient.Email == "":
+		return fmt.Errorf("%w %s", ErrNoAddress, channel)
+	case channel == SMS && recipient.Phone == "":
+		return fmt.Errorf("%w %s", ErrNoAddress, channel)
+	}
+
+	if err := sender.Send(ctx, recipient, message); err != nil {
+		return fmt.Errorf("sending %s to member %d: %w", channel, recipient.MemberID, err)
+	}
+
+	return nil
+}
+
+// unconfigured stands in for the provider of a channel until one is registered.
+var unconfigured = SenderFunc(func(context.Context, Recipient, Message) error {
+	return ErrNoProvider
+})
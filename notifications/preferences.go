@@ -0,0 +1,122 @@
+package notifications
+
+import (
+	"maps"
+	"time"
+)
+
+// Category is the kind of notification a user can opt in or out of per channel.
+type Category string
+
+const (
+	Announcements   Category = "announcements"
+	EventReminders  Category = "event_reminders"
+	RSVPUpdates     Category = "rsvp_updates"
+	PaymentReceipts Category = "payment_receipts"
+)
+
+// ClockLayout is the layout of the quiet hours start and end times.
+const ClockLayout = "15:04"
+
+var (
+	Categories = []Category{Announcements, EventReminders, RSVPUpdates, PaymentReceipts}
+	// PreferenceChannels are the channels a user can opt in or out of.
+	PreferenceChannels = []Channel{Email, SMS, InApp, Push}
+)
+
+// defaults are the channels each category is delivered over until a user says otherwise. SMS costs
+// money and interrupts, so it is opt-in everywhere.
+var defaults = map[Category]map[Channel]bool{
+	Announcements:   {Email: true, SMS: false, InApp: true, Push: true},
+	EventReminders:  {Email: true, SMS: false, InApp: true, Push: true},
+	RSVPUpdates:     {Email: false, SMS: false, InApp: true, Push: true},
+	PaymentReceipts: {Email: true, SMS: false, InApp: true, Push: false},
+}
+
+// Preferences are the channels a user receives each category of notification on, along with the hours
+// they do not want to be disturbed.
+type Preferences struct {
+	Channels   map[Category]map[Channel]bool `json:"channels"`
+	QuietHours *QuietHours                   `json:"quiet_hours"`
+}
+
+// QuietHours is a daily window, in the user's time zone, during which only in-app notifications are
+// delivered. The rest are held back until it ends. A window whose end is before its start spans midnight.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"time_zone"`
+}
+
+// DefaultPreferences returns the preferences of a user who has not changed any.
+func DefaultPreferences() Preferences {
+	channels := make(map[Category]map[Channel]bool, len(defaults))
+	for category, enabled := range defaults {
+		channels[category] = maps.Clone(enabled)
+	}
+	return Preferences{Channels: channels}
+}
+
+// Set overrides whether category is delivered over channel.
+func (p Preferences) Set(category Category, channel Channel, enabled bool) {
+	if p.Channels[category] == nil {
+		p.Channels[category] = make(map[Channel]bool)
+	}
+	p.Channels[category][channel] = enabled
+}
+
+// Allows reports whether category may be delivered over channel.
+func (p Preferences) Allows(category Category, channel Channel) bool {
+	return p.Channels[category][channel]
+}
+
+// DeliverAfter returns when a notification sent over channel at t should go out, or the zero time when it
+// can go out right away. In-app notifications are never held back since they do not interrupt anyone.
+func (p Preferences) DeliverAfter(channel Channel, t time.Time) time.Time {
+	if channel == InApp || p.QuietHours == nil {
+		return time.Time{}
+	}
+	if end, ok := p.QuietHours.Until(t); ok {
+		return end
+	}
+	return time.Time{}
+}
+
+// Until returns the end of the quiet hours t falls within, or false when t is outside of them.
+func (q QuietHours) Until(t time.Time) (time.Time, bool) {
+	start, err := time.Parse(ClockLayout, q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	end, err := time.Parse(ClockLayout, q.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	location, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := t.In(location)
+	at := func(clock time.Time, days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, clock.Hour(), clock.Minute(), 0, 0, location)
+	}
+
+	from, until := at(start, 0), at(end, 0)
+	switch {
+	case from.Equal(until):
+	case from.Before(until):
+		if !local.Before(from) && local.Before(until) {
+			return until, true
+		}
+	case local.Before(until):
+		// The window spans midnight and started yesterday.
+		return until, true
+	case !local.Before(from):
+		return at(end, 1), true
+	}
+
+	return time.Time{}, false
+}
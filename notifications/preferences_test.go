@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("loading time zone %s: %v", name, err)
+	}
+	return location
+}
+
+func TestQuietHoursUntil(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	newYork := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		at    time.Time
+		want  time.Time
+	}{
+		{
+			name:  "within a window of the same day",
+			quiet: QuietHours{Start: "12:00", End: "14:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 2, 13, 0, 0, 0, berlin),
+			want:  time.Date(2025, 6, 2, 14, 0, 0, 0, berlin),
+		},
+		{
+			name:  "at the start of a window",
+			quiet: QuietHours{Start: "12:00", End: "14:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 2, 12, 0, 0, 0, berlin),
+			want:  time.Date(2025, 6, 2, 14, 0, 0, 0, berlin),
+		},
+		{
+			name:  "at the end of a window",
+			quiet: QuietHours{Start: "12:00", End: "14:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 2, 14, 0, 0, 0, berlin),
+		},
+		{
+			name:  "before midnight in a window across midnight",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 2, 23, 30, 0, 0, berlin),
+			want:  time.Date(2025, 6, 3, 7, 0, 0, 0, berlin),
+		},
+		{
+			name:  "after midnight in a window across midnight",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 3, 3, 0, 0, 0, berlin),
+			want:  time.Date(2025, 6, 3, 7, 0, 0, 0, berlin),
+		},
+		{
+			name:  "outside a window across midnight",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 3, 12, 0, 0, 0, berlin),
+		},
+		{
+			name:  "in the user's time zone rather than the sender's",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "America/New_York"},
+			at:    time.Date(2025, 6, 3, 3, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 6, 3, 7, 0, 0, 0, newYork),
+		},
+		{
+			name:  "across the switch to daylight saving time",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 3, 29, 23, 0, 0, 0, berlin),
+			want:  time.Date(2025, 3, 30, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "across the switch back to standard time",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 10, 25, 23, 0, 0, 0, berlin),
+			want:  time.Date(2025, 10, 26, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "starting within the hour skipped by daylight saving time",
+			quiet: QuietHours{Start: "02:30", End: "06:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 3, 30, 4, 0, 0, 0, berlin),
+			want:  time.Date(2025, 3, 30, 6, 0, 0, 0, berlin),
+		},
+		{
+			name:  "in UTC for an unknown time zone",
+			quiet: QuietHours{Start: "22:00", End: "07:00", TimeZone: "Mars/Olympus_Mons"},
+			at:    time.Date(2025, 6, 2, 23, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 6, 3, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "never when the window starts as it ends",
+			quiet: QuietHours{Start: "22:00", End: "22:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 2, 22, 0, 0, 0, berlin),
+		},
+		{
+			name:  "never for a malformed window",
+			quiet: QuietHours{Start: "late", End: "07:00", TimeZone: "Europe/Berlin"},
+			at:    time.Date(2025, 6, 2, 23, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.quiet.Until(tt.at)
+			if ok != !tt.want.IsZero() {
+				t.Fatalf("got within quiet hours %t, want %t", ok, !tt.want.IsZero())
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got end %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreferencesDeliverAfter(t *testing.T) {
+	quiet := &QuietHours{Start: "22:00", End: "07:00", TimeZone: "UTC"}
+	night := time.Date(2025, 6, 2, 23, 0, 0, 0, time.UTC)
+	morning := time.Date(2025, 6, 3, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		preferences Preferences
+		channel     Channel
+		at          time.Time
+		want        time.Time
+	}{
+		{"email within quiet hours", Preferences{QuietHours: quiet}, Email, night, morning},
+		{"push within quiet hours", Preferences{QuietHours: quiet}, Push, night, morning},
+		{"in-app within quiet hours", Preferences{QuietHours: quiet}, InApp, night, time.Time{}},
+		{"email outside of quiet hours", Preferences{QuietHours: quiet}, Email, morning, time.Time{}},
+		{"email without quiet hours", Preferences{}, Email, night, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.preferences.DeliverAfter(tt.channel, tt.at); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Package preferences provides the notification preferences that decide which channels members are
+// notified on, per group, and when.
+package preferences
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+	"github.com/ship-labs/meet-loop-api/notifications"
+)
+
+// Channels maps categories to whether they are delivered over each channel. A null value removes the
+// override, falling back to the user-wide preference for group overrides and to the default otherwise.
+type Channels map[notifications.Category]map[notifications.Channel]*bool
+
+// Load resolves the preferences of every user in userIDs. The defaults are overridden by the user-wide
+// preferences, which are in turn overridden by those for groupID when it is set.
+func Load(ctx context.Context, q *sqlc.Queries, groupID pgtype.Int8, userIDs []pgtype.UUID) (map[pgtype.UUID]notifications.Preferences, error) {
+	overrides, err := q.ListNotificationPreferences(ctx, sqlc.ListNotificationPreferencesParams{
+		UserIds: userIDs,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing notification preferences: %w", err)
+	}
+
+	settings, err := q.ListNotificationSettings(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("listing notification settings: %w", err)
+	}
+
+	return resolve(userIDs, overrides, settings), nil
+}
+
+// resolve applies the overrides and quiet hours of every user in userIDs to the defaults. Group overrides
+// take precedence over user-wide ones whatever order they are listed in.
+func resolve(userIDs []pgtype.UUID, overrides []sqlc.NotificationPreference, settings []sqlc.NotificationSetting) map[pgtype.UUID]notifications.Preferences {
+	preferences := make(map[pgtype.UUID]notifications.Preferences, len(userIDs))
+	for _, userID := range userIDs {
+		preferences[userID] = notifications.DefaultPreferences()
+	}
+
+	for _, group := range []bool{false, true} {
+		for _, override := range overrides {
+			p, ok := preferences[override.UserID]
+			if !ok || override.GroupID.Valid != group {
+				continue
+			}
+			p.Set(notifications.Category(override.Category), notifications.Channel(override.Channel), override.Enabled)
+		}
+	}
+
+	for _, setting := range settings {
+		p, ok := preferences[setting.UserID]
+		if !ok || !setting.QuietHoursStart.Valid {
+			continue
+		}
+		p.QuietHours = &notifications.QuietHours{
+			Start:    clock(setting.QuietHoursStart),
+			End:      clock(setting.QuietHoursEnd),
+			TimeZone: setting.TimeZone,
+		}
+		preferences[setting.UserID] = p
+	}
+
+	return preferences
+}
+
+// GetPreferences returns the caller's user-wide notification preferences.
+func GetPreferences(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		preferences, err := Load(r.Context(), store.Queries, pgtype.Int8{}, []pgtype.UUID{userID})
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    preferences[userID],
+		})
+	}
+}
+
+// UpdatePreferences updates the caller's user-wide notification preferences present in the request and
+// their quiet hours. Sending quiet hours with an empty start and end turns them off.
+func UpdatePreferences(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Channels   Channels                  `json:"channels" zog:"channels"`
+			QuietHours *notifications.QuietHours `json:"quiet_hours" zog:"quiet_hours"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"QuietHours": zog.Ptr(zog.Struct(zog.Shape{
+				"Start":    zog.String().TestFunc(validClock, zog.Message("Quiet hours start must be a time such as 22:00")),
+				"End":      zog.String().TestFunc(validClock, zog.Message("Quiet hours end must be a time such as 07:00")),
+				"TimeZone": zog.String().TestFunc(internal.ValidTimeZone, zog.Message("Time zone must be a valid IANA time zone such as Europe/Berlin")),
+			}).TestFunc(func(val any, ctx zog.Ctx) bool {
+				q := val.(*notifications.QuietHours)
+				return (q.Start == "") == (q.End == "") && (q.Start == "" || q.Start != q.End)
+			}, zog.Message("Quiet hours need both a start and an end, and they must differ"))),
+		})
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating notification preferences: %w", err))
+		}
+
+		if err := body.Channels.validate(); err != nil {
+			return middleware.Error(err)
+		}
+
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			if err := save(r.Context(), q, userID, pgtype.Int8{}, body.Channels); err != nil {
+				return err
+			}
+
+			if body.QuietHours == nil {
+				return nil
+			}
+
+			settings := sqlc.UpsertNotificationSettingsParams{
+				UserID:   userID,
+				TimeZone: body.QuietHours.TimeZone,
+			}
+			if settings.TimeZone == "" {
+				settings.TimeZone = time.UTC.String()
+			}
+			if body.QuietHours.Start != "" {
+				settings.QuietHoursStart = parseClock(body.QuietHours.Start)
+				settings.QuietHoursEnd = parseClock(body.QuietHours.End)
+			}
+
+			if _, err := q.UpsertNotificationSettings(r.Context(), settings); err != nil {
+				return fmt.Errorf("saving quiet hours: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("updating notification preferences: %w", err))
+		}
+
+		preferences, err := Load(r.Context(), store.Queries, pgtype.Int8{}, []pgtype.UUID{userID})
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    preferences[userID],
+		})
+	}
+}
+
+// GetGroupPreferences returns the notification preferences in effect for the caller in a group.
+func GetGroupPreferences(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		member, err := groups.FindMember(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		preferences, err := Load(r.Context(), store.Queries, pgtype.Int8{Int64: groupID, Valid: true}, []pgtype.UUID{member.UserID})
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    preferences[member.UserID],
+		})
+	}
+}
+
+// UpdateGroupPreferences overrides the caller's user-wide notification preferences within a group.
+func UpdateGroupPreferences(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Channels Channels `json:"channels" zog:"channels"`
+		}
+
+		groupID, err := internal.PathID(r, "id")
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		body, err := internal.Validate[Body](zog.Struct(zog.Shape{}), r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating notification preferences: %w", err))
+		}
+
+		if err := body.Channels.validate(); err != nil {
+			return middleware.Error(err)
+		}
+
+		member, err := groups.FindMember(r.Context(), store, groupID)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		group := pgtype.Int8{Int64: groupID, Valid: true}
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			return save(r.Context(), q, member.UserID, group, body.Channels)
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("updating group notification preferences: %w", err))
+		}
+
+		preferences, err := Load(r.Context(), store.Queries, group, []pgtype.UUID{member.UserID})
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    preferences[member.UserID],
+		})
+	}
+}
+
+// validate rejects categories and channels preferences cannot be set for.
+func (c Channels) validate() error {
+	for category, channels := range c {
+		if !slices.Contains(notifications.Categories, category) {
+			return fmt.Errorf("%w: unknown notification category %q", internal.ErrInvalidRequest, category)
+		}
+		for channel := range channels {
+			if !slices.Contains(notifications.PreferenceChannels, channel) {
+				return fmt.Errorf("%w: %s %q", internal.ErrInvalidRequest, notifications.ErrUnsupportedChannel, channel)
+			}
+		}
+	}
+	return nil
+}
+
+// save stores the overrides in channels for groupID, or user-wide when it is not set.
+func save(ctx context.Context, q *sqlc.Queries, userID pgtype.UUID, groupID pgtype.Int8, channels Channels) error {
+	for category, enabled := range channels {
+		for channel, on := range enabled {
+			if on == nil {
+				if err := q.DeleteNotificationPreference(ctx, sqlc.DeleteNotificationPreferenceParams{
+					UserID:   userID,
+					GroupID:  groupID,
+					Category: string(category),
+					Channel:  string(channel),
+				}); err != nil {
+					return fmt.Errorf("removing %s %s preference: %w", category, channel, err)
+				}
+				continue
+			}
+
+			if err := q.UpsertNotificationPreference(ctx, sqlc.UpsertNotificationPreferenceParams{
+				UserID:   userID,
+				GroupID:  groupID,
+				Category: string(category),
+				Channel:  string(channel),
+				Enabled:  *on,
+			}); err != nil {
+				return fmt.Errorf("saving %s %s preference: %w", category, channel, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validClock(s *string, ctx zog.Ctx) bool {
+	_, err := time.Parse(notifications.ClockLayout, *s)
+	return err == nil
+}
+
+// clock formats a time of day column the way quiet hours are sent by clients.
+func clock(t pgtype.Time) string {
+	return time.UnixMicro(t.Microseconds).UTC().Format(notifications.ClockLayout)
+}
+
+func parseClock(s string) pgtype.Time {
+	t, _ := time.Parse(notifications.ClockLayout, s)
+	return pgtype.Time{
+		Microseconds: (time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute).Microseconds(),
+		Valid:        true,
+	}
+}
@@ -0,0 +1,111 @@
+package preferences
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/notifications"
+)
+
+var (
+	alice = pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	bob   = pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	group = pgtype.Int8{Int64: 7, Valid: true}
+)
+
+func override(userID pgtype.UUID, groupID pgtype.Int8, channel notifications.Channel, enabled bool) sqlc.NotificationPreference {
+	return sqlc.NotificationPreference{
+		UserID:   userID,
+		GroupID:  groupID,
+		Category: string(notifications.Announcements),
+		Channel:  string(channel),
+		Enabled:  enabled,
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []sqlc.NotificationPreference
+		channel   notifications.Channel
+		want      map[pgtype.UUID]bool
+	}{
+		{
+			name:    "defaults",
+			channel: notifications.Email,
+			want:    map[pgtype.UUID]bool{alice: true, bob: true},
+		},
+		{
+			name:      "user-wide override",
+			overrides: []sqlc.NotificationPreference{override(alice, pgtype.Int8{}, notifications.Email, false)},
+			channel:   notifications.Email,
+			want:      map[pgtype.UUID]bool{alice: false, bob: true},
+		},
+		{
+			name: "group override over a user-wide one",
+			overrides: []sqlc.NotificationPreference{
+				override(alice, pgtype.Int8{}, notifications.SMS, false),
+				override(alice, group, notifications.SMS, true),
+			},
+			channel: notifications.SMS,
+			want:    map[pgtype.UUID]bool{alice: true, bob: false},
+		},
+		{
+			name: "group override listed before the user-wide one",
+			overrides: []sqlc.NotificationPreference{
+				override(alice, group, notifications.Email, true),
+				override(alice, pgtype.Int8{}, notifications.Email, false),
+			},
+			channel: notifications.Email,
+			want:    map[pgtype.UUID]bool{alice: true, bob: true},
+		},
+		{
+			name: "group override of another user",
+			overrides: []sqlc.NotificationPreference{
+				override(bob, group, notifications.Push, false),
+			},
+			channel: notifications.Push,
+			want:    map[pgtype.UUID]bool{alice: true, bob: false},
+		},
+		{
+			name: "override of a user not asked for",
+			overrides: []sqlc.NotificationPreference{
+				override(pgtype.UUID{Bytes: [16]byte{3}, Valid: true}, group, notifications.Push, false),
+			},
+			channel: notifications.Push,
+			want:    map[pgtype.UUID]bool{alice: true, bob: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preferences := resolve([]pgtype.UUID{alice, bob}, tt.overrides, nil)
+			if len(preferences) != len(tt.want) {
+				t.Fatalf("got preferences of %d users, want %d", len(preferences), len(tt.want))
+			}
+			for userID, want := range tt.want {
+				if got := preferences[userID].Allows(notifications.Announcements, tt.channel); got != want {
+					t.Errorf("got %s allowed %t for user %x, want %t", tt.channel, got, userID.Bytes[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveQuietHours(t *testing.T) {
+	settings := []sqlc.NotificationSetting{
+		{UserID: alice, TimeZone: "Europe/Berlin", QuietHoursStart: parseClock("22:00"), QuietHoursEnd: parseClock("07:00")},
+		{UserID: bob, TimeZone: "Europe/Berlin"},
+	}
+
+	preferences := resolve([]pgtype.UUID{alice, bob}, nil, settings)
+
+	want := notifications.QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"}
+	if got := preferences[alice].QuietHours; got == nil || *got != want {
+		t.Errorf("got quiet hours %+v, want %+v", got, want)
+	}
+	if got := preferences[bob].QuietHours; got != nil {
+		t.Errorf("got quiet hours %+v without a start, want none", got)
+	}
+}
@@ -0,0 +1,239 @@
+// Package privacy handles data subject requests: exporting and erasing everything tied to a user.
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+const (
+	kindExport  = "export"
+	kindErasure = "erasure"
+)
+
+// ExportData responds with a zip archive holding one JSON file per entity tied to the caller: their
+// profile, memberships, RSVPs, payments, authored announcements and received announcements.
+func ExportData(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		var profile *sqlc.Profile
+		p, err := store.GetProfile(r.Context(), userID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return middleware.Error(fmt.Errorf("getting profile: %w", err))
+		}
+		if err == nil {
+			profile = &p
+		}
+
+		memberships, err := store.ListUserMembers(r.Context(), userID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing memberships: %w", err))
+		}
+
+		rsvps, err := store.ListUserRsvps(r.Context(), userID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing rsvps: %w", err))
+		}
+
+		payments := make([]sqlc.Rsvp, 0, len(rsvps))
+		for _, rsvp := range rsvps {
+			if rsvp.HasPaid.Bool || rsvp.PaymentReferenceID.Valid || rsvp.PaymentData != nil {
+				payments = append(payments, rsvp)
+			}
+		}
+
+		announcements, err := store.ListUserAnnouncements(r.Context(), userID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing announcements: %w", err))
+		}
+
+		deliveries, err := store.ListUserAnnouncementDeliveries(r.Context(), userID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing announcement deliveries: %w", err))
+		}
+
+		archive, err := zipJSON(map[string]any{
+			"profile.json":                profile,
+			"memberships.json":            memberships,
+			"rsvps.json":                  rsvps,
+			"payments.json":               payments,
+			"announcements.json":          announcements,
+			"received_announcements.json": deliveries,
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("creating archive: %w", err))
+		}
+
+		if _, err := store.CreatePrivacyRequest(r.Context(), sqlc.CreatePrivacyRequestParams{
+			UserID: userID,
+			Kind:   kindExport,
+		}); err != nil {
+			return middleware.Error(fmt.Errorf("recording export request: %w", err))
+		}
+
+		return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+			filename := fmt.Sprintf("meetloop-export-%s.zip", time.Now().UTC().Format(time.DateOnly))
+			w.Header().Set("Content-Type", "application/zip")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			w.Write(archive)
+			return middleware.OK
+		}
+	}
+}
+
+// EraseAccount anonymizes the caller's personal data. Their profile and memberships are scrubbed and the
+// memberships soft deleted, their admin roles, group ownership and notification preferences are dropped
+// and their sessions revoked, while RSVPs and payments are kept intact for accounting. The erasure is
+// recorded in the privacy audit trail. It is refused while the caller is the only admin of a group.
+func EraseAccount(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			Confirm bool `json:"confirm" zog:"confirm"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"Confirm": zog.Bool().Required(zog.Message("Erasure must be confirmed, it cannot be undone")),
+		})
+
+		// Required rejects false as well, so a body without "confirm": true never gets through.
+		if _, err := internal.Validate[Body](v, r.Body); err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating erasure: %w", err))
+		}
+
+		userID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		// Groups must not be left without an admin, so their admin role has to be handed over first.
+		soleAdmin, err := store.ListSoleAdminGroups(r.Context(), userID)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("listing groups without another admin: %w", err))
+		}
+		if len(soleAdmin) > 0 {
+			names := make([]string, 0, len(soleAdmin))
+			for _, group := range soleAdmin {
+				names = append(names, strconv.Quote(group.Name))
+			}
+			return middleware.Error(fmt.Errorf("%w: appoint another admin of %s before erasing your account",
+				internal.ErrConflict, strings.Join(names, ", ")))
+		}
+
+		var request sqlc.PrivacyRequest
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			if err := q.AnonymizeProfile(r.Context(), userID); err != nil {
+				return fmt.Errorf("anonymizing profile: %w", err)
+			}
+
+			admins, err := q.DeleteUserGroupAdmins(r.Context(), userID)
+			if err != nil {
+				return fmt.Errorf("deleting group admin roles: %w", err)
+			}
+
+			groups, err := q.ClearUserGroupOwnership(r.Context(), userID)
+			if err != nil {
+				return fmt.Errorf("clearing group ownership: %w", err)
+			}
+
+			members, err := q.AnonymizeUserMembers(r.Context(), userID)
+			if err != nil {
+				return fmt.Errorf("anonymizing memberships: %w", err)
+			}
+
+			preferences, err := q.DeleteUserNotificationPreferences(r.Context(), userID)
+			if err != nil {
+				return fmt.Errorf("deleting notification preferences: %w", err)
+			}
+
+			if err := q.DeleteUserNotificationSettings(r.Context(), userID); err != nil {
+				return fmt.Errorf("deleting notification settings: %w", err)
+			}
+
+			// Tokens issued so far stop working once the erasure commits.
+			if _, err := q.CreateSessionRevocation(r.Context(), sqlc.CreateSessionRevocationParams{
+				UserID:    userID,
+				Reason:    pgtype.Text{String: "account erased", Valid: true},
+				RevokedBy: userID,
+			}); err != nil {
+				return fmt.Errorf("revoking sessions: %w", err)
+			}
+
+			details, err := json.Marshal(map[string]int64{
+				"memberships_anonymized": members,
+				"admin_roles_removed":    admins,
+				"groups_disowned":        groups,
+				"preferences_deleted":    preferences,
+			})
+			if err != nil {
+				return fmt.Errorf("marshalling details: %w", err)
+			}
+
+			request, err = q.CreatePrivacyRequest(r.Context(), sqlc.CreatePrivacyRequestParams{
+				UserID:  userID,
+				Kind:    kindErasure,
+				Details: details,
+			})
+			if err != nil {
+				return fmt.Errorf("recording erasure request: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("erasing account: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    request,
+		})
+	}
+}
+
+// zipJSON encodes every value as an indented JSON file of a zip archive, keyed by file name.
+func zipJSON(files map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, value := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s: %w", name, err)
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
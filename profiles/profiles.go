@@ -0,0 +1,185 @@
+// Package profiles provides the user profiles shown to other members across every group.
+package profiles
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Oudwins/zog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+const (
+	maxInterests      = 20
+	maxInterestLength = 50
+)
+
+// Ensure returns the caller's profile, creating it from their token claims on their first request.
+func Ensure(ctx context.Context, store *sqlc.Store) (sqlc.Profile, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return sqlc.Profile{}, fmt.Errorf("getting user ID: %w", err)
+	}
+
+	profile, err := store.GetProfile(ctx, userID)
+	if err == nil {
+		return profile, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return profile, fmt.Errorf("getting profile: %w", err)
+	}
+
+	claims, err := middleware.GetClaims(ctx)
+	if err != nil {
+		return profile, fmt.Errorf("getting claims: %w", err)
+	}
+
+	localPart, _, _ := strings.Cut(claims.Email, "@")
+	avatarURL := claims.UserMetadata.AvatarURL
+
+	profile, err = store.CreateProfile(ctx, sqlc.CreateProfileParams{
+		UserID:      userID,
+		DisplayName: cmp.Or(claims.UserMetadata.Name, localPart),
+		AvatarUrl:   pgtype.Text{String: avatarURL, Valid: avatarURL != ""},
+	})
+	if err != nil {
+		return profile, fmt.Errorf("creating profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfile updates the fields of the caller's profile present in the request. Sending an empty
+// bio, avatar_url or city clears it. A phone number, normalized to E.164 with phoneRegion when it has no
+// country code, becomes the contact phone of every membership of the caller.
+func UpdateProfile(store *sqlc.Store, phoneRegion string) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			DisplayName *string  `json:"display_name" zog:"display_name"`
+			Bio         *string  `json:"bio" zog:"bio"`
+			AvatarURL   *string  `json:"avatar_url" zog:"avatar_url"`
+			City        *string  `json:"city" zog:"city"`
+			Phone       *string  `json:"phone" zog:"phone"`
+			Interests   []string `json:"interests" zog:"interests"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			// Optional fields skip their tests on empty values, which Required catches instead.
+			"DisplayName": zog.Ptr(zog.String().Required(zog.Message("Display name cannot be empty")).
+				TestFunc(func(name *string, ctx zog.Ctx) bool {
+					return strings.TrimSpace(*name) != ""
+				}, zog.Message("Display name cannot be empty")).
+				Max(100, zog.Message("Display name must be at most 100 characters"))),
+			"Bio":  zog.Ptr(zog.String().Trim().Max(500, zog.Message("Bio must be at most 500 characters"))),
+			"City": zog.Ptr(zog.String().Trim().Max(100, zog.Message("City must be at most 100 characters"))),
+			"Phone": zog.Ptr(zog.String().Trim().
+				TestFunc(internal.ValidPhone(phoneRegion), zog.Message("Phone must be a valid phone number"))),
+			"AvatarURL": zog.Ptr(zog.String().Trim().TestFunc(func(avatar *string, ctx zog.Ctx) bool {
+				if *avatar == "" {
+					return true
+				}
+				u, err := url.ParseRequestURI(*avatar)
+				return err == nil && (u.Scheme == "https" || u.Scheme == "http")
+			}, zog.Message("Avatar URL must be a valid http or https URL"))),
+			"Interests": zog.Slice(zog.String().
+				Max(maxInterestLength, zog.Message(fmt.Sprintf("Interests must be at most %d characters", maxInterestLength)))).
+				Max(maxInterests, zog.Message(fmt.Sprintf("You can have at most %d interests", maxInterests))),
+		})
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating profile: %w", err))
+		}
+
+		current, err := Ensure(r.Context(), store)
+		if err != nil {
+			return middleware.Error(err)
+		}
+
+		phone := sqlc.UpdateUserPhoneParams{UserID: current.UserID}
+		if body.Phone != nil && strings.TrimSpace(*body.Phone) != "" {
+			e164, err := internal.NormalizePhone(*body.Phone, phoneRegion)
+			if err != nil {
+				return middleware.Error(err)
+			}
+			phone.Phone = e164
+			phone.PhoneE164 = pgtype.Text{String: e164, Valid: true}
+		}
+
+		var profile sqlc.Profile
+
+		err = store.ExecuteTransaction(r.Context(), func(q *sqlc.Queries) error {
+			profile, err = q.UpdateProfile(r.Context(), sqlc.UpdateProfileParams{
+				DisplayName: text(body.DisplayName),
+				Bio:         text(body.Bio),
+				AvatarUrl:   text(body.AvatarURL),
+				City:        text(body.City),
+				Interests:   normalizeInterests(body.Interests),
+				UserID:      current.UserID,
+			})
+			if err != nil {
+				return fmt.Errorf("saving profile: %w", err)
+			}
+
+			if body.Phone != nil {
+				if _, err := q.UpdateUserPhone(r.Context(), phone); err != nil {
+					return fmt.Errorf("updating membership phones: %w", err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return middleware.Error(fmt.Errorf("updating profile: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusOK),
+			Data:    profile,
+		})
+	}
+}
+
+// text converts an optional request field into a nullable column value, where nil leaves it unchanged.
+func text(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: strings.TrimSpace(*s), Valid: true}
+}
+
+// normalizeInterests trims every interest and drops empty values and case-insensitive duplicates while
+// preserving order. A nil slice stays nil so the stored interests are left unchanged.
+func normalizeInterests(raw []string) []string {
+	if raw == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(raw))
+	interests := make([]string, 0, len(raw))
+
+	for _, interest := range raw {
+		interest = strings.TrimSpace(interest)
+		key := strings.ToLower(interest)
+		if interest == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		interests = append(interests, interest)
+	}
+
+	return interests
+}
@@ -0,0 +1,85 @@
+// Package sessions provides handlers for revoking the sessions of users, such as when they are banned or
+// their account is compromised.
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Oudwins/zog"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ship-labs/meet-loop-api/groups"
+	"github.com/ship-labs/meet-loop-api/internal"
+	"github.com/ship-labs/meet-loop-api/internal/pkg/sqlc"
+	"github.com/ship-labs/meet-loop-api/middleware"
+)
+
+// RevokeSessions revokes one session of a user when session_id is given, and otherwise every session
+// they signed in to so far. The API rejects the access tokens of revoked sessions within seconds,
+// instead of accepting them until they expire. Platform admins only.
+func RevokeSessions(store *sqlc.Store) middleware.Handler {
+	return func(w http.ResponseWriter, r *http.Request) middleware.Handler {
+		type Body struct {
+			SessionID *string `json:"session_id" zog:"session_id"`
+			Reason    *string `json:"reason" zog:"reason"`
+		}
+
+		v := zog.Struct(zog.Shape{
+			"SessionID": zog.Ptr(zog.String().UUID(zog.Message("Session ID must be a UUID"))),
+			"Reason":    zog.Ptr(zog.String().Trim().Max(500, zog.Message("Reason must be at most 500 characters"))),
+		})
+
+		value := r.PathValue("userID")
+		userID, err := uuid.Parse(value)
+		if err != nil {
+			return middleware.Error(fmt.Errorf("%w: invalid userID %q", internal.ErrInvalidRequest, value))
+		}
+
+		body, err := internal.Validate[Body](v, r.Body)
+		if err != nil {
+			var v internal.ValidationError
+			if errors.As(err, &v) {
+				return middleware.Error(v)
+			}
+			return middleware.Error(fmt.Errorf("validating session revocation: %w", err))
+		}
+
+		if err := groups.RequirePlatformAdmin(r.Context(), store); err != nil {
+			return middleware.Error(err)
+		}
+
+		adminID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			return middleware.Error(fmt.Errorf("getting user ID: %w", err))
+		}
+
+		params := sqlc.CreateSessionRevocationParams{
+			UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+			RevokedBy: adminID,
+		}
+		if body.SessionID != nil {
+			params.SessionID = pgtype.UUID{Bytes: uuid.MustParse(*body.SessionID), Valid: true}
+		}
+		if body.Reason != nil && strings.TrimSpace(*body.Reason) != "" {
+			params.Reason = pgtype.Text{String: *body.Reason, Valid: true}
+		}
+
+		revocation, err := store.CreateSessionRevocation(r.Context(), params)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == internal.ForeignKeyViolationCode {
+				return middleware.Error(fmt.Errorf("user %w", internal.ErrNotExist))
+			}
+			return middleware.Error(fmt.Errorf("revoking sessions: %w", err))
+		}
+
+		return middleware.JSON(middleware.Response{
+			Message: http.StatusText(http.StatusCreated),
+			Data:    revocation,
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Package telemetry sets up the OpenTelemetry tracing of the API.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ship-labs/meet-loop-api/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// ServiceName is the name spans are exported under.
+const ServiceName = "meet-loop-api"
+
+// Setup installs the global tracer provider and W3C trace context propagation configured by cfg, and
+// returns a function that flushes the spans still buffered on shutdown. With no exporter configured,
+// spans are neither recorded nor exported.
+func Setup(ctx context.Context, cfg config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TracingExporter {
+	case config.OTLPTracingExporter:
+		var options []otlptracehttp.Option
+		if cfg.TracingEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.TracingEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case config.StdoutTracingExporter:
+		exporter, err = stdouttrace.New()
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s span exporter: %w", cfg.TracingExporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.DeploymentEnvironmentName(cfg.Env)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}